@@ -0,0 +1,130 @@
+package changelog
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"planq.dev/planq/internal/statefile"
+)
+
+// headerPattern matches an entry header such as
+// "## 2026-01-21T10:30:00Z - implement - completed".
+var headerPattern = regexp.MustCompile(`^## (\d{4}-\d{2}-\d{2}T\S+) - ([a-z_]+)(?: - ([a-z_]+))?$`)
+
+// Append validates e and adds it to the changelog at path.
+// A zero Time is set to now.
+func Append(path string, e Entry) (*Entry, error) {
+	e.Message = strings.TrimSpace(e.Message)
+	if e.Message == "" {
+		return nil, fmt.Errorf("changelog message is empty")
+	}
+	if _, err := ParseType(string(e.Type)); err != nil {
+		return nil, err
+	}
+	if e.Status != "" {
+		if _, err := ParseStatus(string(e.Status)); err != nil {
+			return nil, err
+		}
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Time = e.Time.UTC().Truncate(time.Second)
+
+	if err := statefile.Append(path, []byte(format(&e)), 0644); err != nil {
+		return nil, fmt.Errorf("failed to append to changelog: %w", err)
+	}
+	return &e, nil
+}
+
+// format renders an entry as a markdown section.
+func format(e *Entry) string {
+	header := fmt.Sprintf("## %s - %s", e.Time.Format(time.RFC3339), e.Type)
+	if e.Status != "" {
+		header += " - " + string(e.Status)
+	}
+	return fmt.Sprintf("%s\n\n%s\n\n", header, e.Message)
+}
+
+// Read returns the entries of the changelog at path, oldest first.
+// A missing file has no entries.
+func Read(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read changelog: %w", err)
+	}
+	return Parse(string(data)), nil
+}
+
+// Parse splits changelog content into entries. Text before the first entry
+// header and headers with an unknown timestamp are ignored.
+func Parse(content string) []Entry {
+	var entries []Entry
+	var current *Entry
+	var body []string
+
+	flush := func() {
+		if current != nil {
+			current.Message = strings.TrimSpace(strings.Join(body, "\n"))
+			entries = append(entries, *current)
+		}
+		current, body = nil, nil
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		if m := headerPattern.FindStringSubmatch(strings.TrimRight(line, " \r")); m != nil {
+			if t, err := time.Parse(time.RFC3339, m[1]); err == nil {
+				flush()
+				current = &Entry{Time: t, Type: Type(m[2]), Status: Status(m[3])}
+				continue
+			}
+		}
+		if current != nil {
+			body = append(body, line)
+		}
+	}
+	flush()
+
+	return entries
+}
+
+// Filter returns the entries of the given type, or all entries if t is empty.
+func Filter(entries []Entry, t Type) []Entry {
+	if t == "" {
+		return entries
+	}
+	var filtered []Entry
+	for _, e := range entries {
+		if e.Type == t {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// Merge combines the changelogs of several workspaces, keyed by workspace
+// name, into one timeline ordered by time.
+func Merge(logs map[string][]Entry) []Entry {
+	var merged []Entry
+	for agent, entries := range logs {
+		for _, e := range entries {
+			e.Agent = agent
+			merged = append(merged, e)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if !merged[i].Time.Equal(merged[j].Time) {
+			return merged[i].Time.Before(merged[j].Time)
+		}
+		return merged[i].Agent < merged[j].Agent
+	})
+	return merged
+}
@@ -0,0 +1,76 @@
+package changelog
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppendAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changelog.md")
+
+	entries, err := Read(path)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Read() of a missing file = %v, %v", entries, err)
+	}
+
+	first, err := Append(path, Entry{Type: TypePlan, Message: "Chose RS256 for tokens"})
+	if err != nil {
+		t.Fatalf("Append() failed: %v", err)
+	}
+	if first.Time.IsZero() || first.Time.Location() != time.UTC {
+		t.Errorf("Append() time = %v, want now in UTC", first.Time)
+	}
+	if _, err := Append(path, Entry{Type: TypeImplement, Status: StatusCompleted, Message: "Added ValidateToken\n\n## Notes\nnot a header"}); err != nil {
+		t.Fatalf("Append() failed: %v", err)
+	}
+
+	entries, err = Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Read() returned %d entries, want 2: %+v", len(entries), entries)
+	}
+	if entries[0].Type != TypePlan || entries[0].Status != "" || entries[0].Message != "Chose RS256 for tokens" {
+		t.Errorf("entries[0] = %+v", entries[0])
+	}
+	if entries[1].Status != StatusCompleted || entries[1].Message != "Added ValidateToken\n\n## Notes\nnot a header" {
+		t.Errorf("entries[1] = %+v", entries[1])
+	}
+
+	if _, err := Append(path, Entry{Type: "coding", Message: "x"}); err == nil {
+		t.Error("Append() accepted an invalid type")
+	}
+	if _, err := Append(path, Entry{Type: TypeEnd, Status: "done", Message: "x"}); err == nil {
+		t.Error("Append() accepted an invalid status")
+	}
+	if _, err := Append(path, Entry{Type: TypeEnd, Message: "  "}); err == nil {
+		t.Error("Append() accepted an empty message")
+	}
+}
+
+func TestMergeAndFilter(t *testing.T) {
+	at := func(minute int) time.Time {
+		return time.Date(2026, 1, 20, 10, minute, 0, 0, time.UTC)
+	}
+	merged := Merge(map[string][]Entry{
+		"auth": {{Time: at(0), Type: TypeStart}, {Time: at(30), Type: TypeHandoff}},
+		"cli":  {{Time: at(10), Type: TypeStart}, {Time: at(30), Type: TypeEnd}},
+	})
+
+	want := []string{"auth", "cli", "auth", "cli"}
+	if len(merged) != len(want) {
+		t.Fatalf("Merge() returned %d entries, want %d", len(merged), len(want))
+	}
+	for i, agent := range want {
+		if merged[i].Agent != agent {
+			t.Errorf("merged[%d].Agent = %q, want %q", i, merged[i].Agent, agent)
+		}
+	}
+
+	starts := Filter(merged, TypeStart)
+	if len(starts) != 2 {
+		t.Errorf("Filter(start) returned %d entries, want 2", len(starts))
+	}
+}
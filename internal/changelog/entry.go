@@ -0,0 +1,76 @@
+// Package changelog keeps an append-only log of what an agent did in a workspace.
+package changelog
+
+import (
+	"fmt"
+	"time"
+)
+
+// Type is the kind of work an entry records.
+type Type string
+
+const (
+	// TypeStart marks the beginning of an agent session.
+	TypeStart Type = "start"
+	// TypePlan records design work and approach selection.
+	TypePlan Type = "plan"
+	// TypeImplement records active coding work.
+	TypeImplement Type = "implement"
+	// TypeDecision records why an approach was chosen.
+	TypeDecision Type = "decision"
+	// TypeHandoff gives another agent the context to continue.
+	TypeHandoff Type = "handoff"
+	// TypeEnd marks the completion of a work segment.
+	TypeEnd Type = "end"
+)
+
+// Types lists every valid entry type.
+var Types = []Type{TypeStart, TypePlan, TypeImplement, TypeDecision, TypeHandoff, TypeEnd}
+
+// ParseType validates an entry type.
+func ParseType(s string) (Type, error) {
+	for _, t := range Types {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("invalid entry type %q (expected one of %v)", s, Types)
+}
+
+// Status is the progress of the work an entry records.
+type Status string
+
+const (
+	// StatusPending work has not started yet.
+	StatusPending Status = "pending"
+	// StatusInProgress work is under way.
+	StatusInProgress Status = "in_progress"
+	// StatusCompleted work is finished.
+	StatusCompleted Status = "completed"
+	// StatusBlocked work cannot continue without help.
+	StatusBlocked Status = "blocked"
+)
+
+// Statuses lists every valid status.
+var Statuses = []Status{StatusPending, StatusInProgress, StatusCompleted, StatusBlocked}
+
+// ParseStatus validates a status.
+func ParseStatus(s string) (Status, error) {
+	for _, status := range Statuses {
+		if string(status) == s {
+			return status, nil
+		}
+	}
+	return "", fmt.Errorf("invalid status %q (expected one of %v)", s, Statuses)
+}
+
+// Entry is a single changelog entry.
+type Entry struct {
+	Time time.Time `json:"time"`
+	// Agent is the workspace the entry was logged in. It is not stored in
+	// the changelog file, which belongs to a single workspace.
+	Agent   string `json:"agent,omitempty"`
+	Type    Type   `json:"type"`
+	Status  Status `json:"status,omitempty"`
+	Message string `json:"message"`
+}
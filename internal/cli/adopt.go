@@ -0,0 +1,236 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"planq.dev/planq/internal/config"
+	"planq.dev/planq/internal/git"
+	"planq.dev/planq/internal/state"
+	"planq.dev/planq/internal/tmux"
+	"planq.dev/planq/internal/workspace"
+)
+
+var (
+	adoptName   string
+	adoptAgent  string
+	adoptDetach bool
+)
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt <path|session>",
+	Short: "Manage an existing worktree or tmux session as a workspace",
+	Long: `Turn an existing git worktree or tmux session into a planq workspace.
+
+Given a directory, its worktree gets a .planq directory and a tmux session
+with the standard layout. Given a tmux session, the worktree it was started
+in is adopted and the session is kept, renamed to planq-<name>.
+
+The workspace name defaults to the session name or the worktree directory name.
+Existing plan files and agent notes are kept, and 'planq remove' leaves an
+adopted worktree in place.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return adoptWorkspace(args[0], adoptName, adoptAgent, adoptDetach)
+	},
+}
+
+func init() {
+	adoptCmd.Flags().StringVarP(&adoptName, "name", "n", "", "Workspace name (default: session or directory name)")
+	adoptCmd.Flags().StringVar(&adoptAgent, "agent", "", fmt.Sprintf("Agent backend (%s) (default: agent.name from config)", strings.Join(workspace.AgentNames(), ", ")))
+	adoptCmd.Flags().BoolVarP(&adoptDetach, "detach", "d", false, "Adopt without opening the workspace")
+}
+
+// adoptWorkspace registers an existing directory or tmux session as a workspace.
+func adoptWorkspace(target, name, agentName string, detach bool) error {
+	tm, err := tmux.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize tmux: %w", err)
+	}
+
+	// Work out the directory and the session to keep, if any
+	dir, session, err := adoptTarget(tm, target)
+	if err != nil {
+		return err
+	}
+
+	worktree, err := git.GetRepoRootIn(dir)
+	if err != nil {
+		return fmt.Errorf("%s is not inside a git worktree: %w", dir, err)
+	}
+	if name == "" {
+		name = filepath.Base(worktree)
+		if session != "" {
+			name = strings.TrimPrefix(session, sessionPrefix)
+		}
+	}
+	sessionName := sessionPrefix + name
+
+	if meta, err := workspace.ReadMetadata(worktree); err != nil {
+		return err
+	} else if meta != nil {
+		return fmt.Errorf("%s is already the planq workspace %q, use 'planq open %s' to open it", worktree, meta.Name, meta.Name)
+	}
+	if ws, _, err := findWorkspace(name); err == nil {
+		return fmt.Errorf("workspace %q already exists at %s", name, ws.WorktreePath)
+	}
+	if session != sessionName {
+		exists, err := tm.SessionExists(sessionName)
+		if err != nil {
+			return fmt.Errorf("failed to check session: %w", err)
+		}
+		if exists {
+			return fmt.Errorf("session %q already exists", sessionName)
+		}
+	}
+
+	ws, cfg, cleanup, err := adoptWorktree(worktree, name, agentName)
+	if err != nil {
+		return err
+	}
+
+	if session != "" {
+		if session != sessionName {
+			fmt.Printf("  Renaming tmux session %q to %q...\n", session, sessionName)
+			if err := tm.RenameSession(session, sessionName); err != nil {
+				cleanup()
+				return err
+			}
+		}
+		mode, err := ws.GetMode()
+		if err != nil {
+			mode = workspace.ModePlan
+		}
+		configureSession(tm, ws, mode, agentName)
+	} else {
+		agentCmd, err := ws.AgentCommand()
+		if err != nil {
+			cleanup()
+			return fmt.Errorf("failed to build agent command: %w", err)
+		}
+		fmt.Printf("  Creating tmux session %q...\n", sessionName)
+		if err := startSession(tm, ws, cfg, agentCmd, agentName); err != nil {
+			cleanup()
+			return fmt.Errorf("failed to create tmux session: %w", err)
+		}
+	}
+
+	if detach {
+		fmt.Println()
+		fmt.Printf("To open: planq open %s\n", name)
+		return nil
+	}
+
+	return tm.AttachSession(sessionName)
+}
+
+// adoptWorktree sets up the worktree as a workspace and registers it. The
+// returned cleanup undoes this if a later step fails.
+func adoptWorktree(worktree, name, agentName string) (*workspace.Workspace, *config.Config, func(), error) {
+	repoRoot, err := git.GetMainRepoRoot(worktree)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	isMain := samePath(repoRoot, worktree)
+
+	cfg, err := config.Load(worktree)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	fmt.Printf("Adopting %s as workspace %q...\n", worktree, name)
+
+	if isMain {
+		// The main worktree is never removed, so record it like planq create --main
+		err := state.Update(func(globalState *state.GlobalState) error {
+			if existing, exists := globalState.GetMainWorkspace(repoRoot); exists {
+				return fmt.Errorf("main workspace %q already exists for this repository; remove it first with 'planq remove %s'", existing.Name, existing.Name)
+			}
+			globalState.SetMainWorkspace(repoRoot, name)
+			return nil
+		})
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	ws := &workspace.Workspace{Name: name, WorktreePath: worktree}
+	configureAgent(cfg, ws, workspace.ModePlan, agentName)
+
+	// cleanup undoes the adoption if a later step fails: the .planq directory
+	// if adopt created it (or else the metadata), and the state registrations
+	createdPlanqDir := !dirExists(ws.PlanqDir())
+	cleanup := func() {
+		if createdPlanqDir {
+			_ = os.RemoveAll(ws.PlanqDir())
+		} else {
+			_ = os.Remove(ws.MetadataFile())
+		}
+		_ = state.Update(func(globalState *state.GlobalState) error {
+			if isMain {
+				globalState.RemoveMainWorkspace(repoRoot)
+			}
+			globalState.UnregisterWorkspace(worktree)
+			return nil
+		})
+	}
+
+	fmt.Printf("  Initializing .planq directory...\n")
+	if _, statErr := os.Stat(ws.PlanFile()); os.IsNotExist(statErr) {
+		if err := ws.InitPlanqDir(nil); err != nil {
+			cleanup()
+			return nil, nil, nil, fmt.Errorf("failed to initialize .planq directory: %w", err)
+		}
+	}
+	meta := newMetadata(cfg, ws, repoRoot, "", "", agentName, "", isMain)
+	meta.Adopted = true
+	if err := ws.WriteMetadata(meta); err != nil {
+		cleanup()
+		return nil, nil, nil, err
+	}
+	if err := registerWorkspace(meta); err != nil {
+		cleanup()
+		return nil, nil, nil, err
+	}
+
+	fmt.Printf("  Initializing .agent directory...\n")
+	if _, statErr := os.Stat(ws.AgentDir()); os.IsNotExist(statErr) {
+		if err := ws.InitAgentDir(); err != nil {
+			cleanup()
+			return nil, nil, nil, fmt.Errorf("failed to initialize .agent directory: %w", err)
+		}
+	} else if err := ws.InstallAgent(); err != nil {
+		cleanup()
+		return nil, nil, nil, err
+	}
+
+	return ws, cfg, cleanup, nil
+}
+
+// adoptTarget resolves the adopt argument to a directory and, when it names
+// a tmux session, that session's name.
+func adoptTarget(tm *tmux.Manager, target string) (string, string, error) {
+	if info, err := os.Stat(target); err == nil && info.IsDir() {
+		dir, err := filepath.Abs(target)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to resolve %s: %w", target, err)
+		}
+		return dir, "", nil
+	}
+
+	for _, name := range []string{target, sessionPrefix + target} {
+		session, err := tm.GetSession(name)
+		if err != nil {
+			continue
+		}
+		if session.Path == "" {
+			return "", "", fmt.Errorf("session %q has no working directory", name)
+		}
+		return session.Path, name, nil
+	}
+
+	return "", "", fmt.Errorf("%q is neither a directory nor a tmux session", target)
+}
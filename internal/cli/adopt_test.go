@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"planq.dev/planq/internal/state"
+	"planq.dev/planq/internal/workspace"
+)
+
+// gitIn runs a git command in dir.
+func gitIn(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestAdoptThenRemoveKeepsWorktree(t *testing.T) {
+	t.Setenv(state.HomeEnv, t.TempDir())
+	t.Setenv("PLANQ_WORKSPACE", "")
+
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	worktree := filepath.Join(root, "hand-made")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	gitIn(t, repo, "init", "-q")
+	gitIn(t, repo, "commit", "-q", "--allow-empty", "-m", "init")
+	gitIn(t, repo, "worktree", "add", "-q", "-b", "hand-made", worktree)
+
+	ws, _, _, err := adoptWorktree(worktree, "hand-made", "")
+	if err != nil {
+		t.Fatalf("adoptWorktree() error = %v", err)
+	}
+	meta, err := workspace.ReadMetadata(worktree)
+	if err != nil || meta == nil || !meta.Adopted || meta.Main {
+		t.Fatalf("metadata = %+v, %v; want an adopted workspace", meta, err)
+	}
+	globalState, err := state.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, registered := globalState.Workspaces[worktree]; !registered {
+		t.Fatalf("adopted worktree not registered: %+v", globalState.Workspaces)
+	}
+
+	if err := removeWorkspace("hand-made"); err != nil {
+		t.Fatalf("removeWorkspace() error = %v", err)
+	}
+
+	if _, err := os.Stat(worktree); err != nil {
+		t.Errorf("adopted worktree was removed: %v", err)
+	}
+	if _, err := os.Stat(ws.MetadataFile()); !os.IsNotExist(err) {
+		t.Errorf("workspace metadata still present: %v", err)
+	}
+	if _, err := os.Stat(ws.AgentDir()); !os.IsNotExist(err) {
+		t.Errorf("agent directory still present: %v", err)
+	}
+	globalState, err = state.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, registered := globalState.Workspaces[worktree]; registered {
+		t.Error("adopted worktree still registered after remove")
+	}
+}
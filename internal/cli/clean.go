@@ -2,9 +2,9 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
-	"planq.dev/planq/internal/stackit"
 	"planq.dev/planq/internal/tmux"
 )
 
@@ -13,7 +13,7 @@ var cleanDryRun bool
 var cleanCmd = &cobra.Command{
 	Use:   "clean",
 	Short: "Clean up orphaned workspaces",
-	Long:  `Remove tmux sessions that no longer have corresponding worktrees.`,
+	Long:  `Remove tmux sessions whose workspace no longer exists on disk.`,
 	Args:  cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return cleanOrphaned()
@@ -26,16 +26,6 @@ func init() {
 
 // cleanOrphaned removes orphaned tmux sessions.
 func cleanOrphaned() error {
-	// Get worktree names
-	worktreeNames := make(map[string]bool)
-	st := stackit.NewClient()
-	worktrees, err := st.WorktreeList()
-	if err == nil {
-		for _, wt := range worktrees {
-			worktreeNames[wt.Name] = true
-		}
-	}
-
 	// Get tmux sessions
 	tm, err := tmux.NewManager()
 	if err != nil {
@@ -48,14 +38,16 @@ func cleanOrphaned() error {
 		return nil
 	}
 
-	// Find orphaned sessions
+	sessionNames := make(map[string]bool)
+	for _, s := range sessions {
+		sessionNames[strings.TrimPrefix(s.Name, sessionPrefix)] = true
+	}
+
+	// Sessions are orphaned when no workspace on disk claims them
+	known := workspaceNames(knownWorkspaces(sessionNames))
 	var orphaned []string
 	for _, s := range sessions {
-		name := s.Name
-		if len(s.Name) > len(sessionPrefix) {
-			name = s.Name[len(sessionPrefix):]
-		}
-		if !worktreeNames[name] {
+		if !known[strings.TrimPrefix(s.Name, sessionPrefix)] {
 			orphaned = append(orphaned, s.Name)
 		}
 	}
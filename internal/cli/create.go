@@ -2,10 +2,16 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"planq.dev/planq/internal/config"
 	"planq.dev/planq/internal/deps"
 	"planq.dev/planq/internal/git"
+	"planq.dev/planq/internal/plan"
+	"planq.dev/planq/internal/queue"
 	"planq.dev/planq/internal/stackit"
 	"planq.dev/planq/internal/state"
 	"planq.dev/planq/internal/tmux"
@@ -14,7 +20,9 @@ import (
 
 var (
 	createScope    string
+	createAgent    string
 	createAgentCmd string
+	createTemplate string
 	createDetach   bool
 	createMain     bool
 )
@@ -25,23 +33,98 @@ var createCmd = &cobra.Command{
 	Long:  `Create a new workspace with a git worktree and tmux session.`,
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return createWorkspace(args[0], createScope, createAgentCmd, createDetach, createMain)
+		return createWorkspace(args[0], createOptions{
+			Scope:    createScope,
+			Agent:    createAgent,
+			AgentCmd: createAgentCmd,
+			Template: createTemplate,
+			Detach:   createDetach,
+			Main:     createMain,
+		})
 	},
 }
 
 func init() {
 	createCmd.Flags().StringVarP(&createScope, "scope", "s", "", "Scope for worktree (optional)")
-	createCmd.Flags().StringVarP(&createAgentCmd, "agent-cmd", "a", "", "Command to run in agent pane (default: claude)")
+	createCmd.Flags().StringVar(&createAgent, "agent", "", fmt.Sprintf("Agent backend (%s) (default: agent.name from config)", strings.Join(workspace.AgentNames(), ", ")))
+	createCmd.Flags().StringVarP(&createAgentCmd, "agent-cmd", "a", "", "Command to run in agent pane in every mode, replacing the configured agent (default: agent.command from config)")
+	createCmd.Flags().StringVarP(&createTemplate, "template", "t", "", "Plan template to seed the plan file, e.g. bugfix, feature, refactor, spike (default: create.template from config)")
 	createCmd.Flags().BoolVarP(&createDetach, "detach", "d", false, "Create workspace without opening it")
 	createCmd.Flags().BoolVar(&createMain, "main", false, "Use main worktree instead of creating a new one (for testing)")
 }
 
+// createOptions configure a new workspace.
+type createOptions struct {
+	Scope    string
+	Agent    string
+	AgentCmd string
+	Template string
+	Detach   bool
+	Main     bool
+	// Plan seeds the plan file instead of a template.
+	Plan []byte
+	// InitialPrompt is sent to the agent when it first starts.
+	InitialPrompt string
+	// QueueItem is claimed by the workspace in the queue of QueueRoot.
+	QueueItem int
+	QueueRoot string
+}
+
 // createWorkspace creates a new workspace with worktree + tmux session.
-func createWorkspace(name, scope, agentCmd string, detach, useMain bool) error {
+func createWorkspace(name string, opts createOptions) error {
+	if opts.QueueItem != 0 && opts.QueueRoot == "" {
+		return fmt.Errorf("queue item #%d has no queue root", opts.QueueItem)
+	}
 	sessionName := sessionPrefix + name
+	scope, agentName, agentCmd, templateName := opts.Scope, opts.Agent, opts.AgentCmd, opts.Template
+
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return fmt.Errorf("failed to get repository root: %w", err)
+	}
+
+	cfg, err := config.Load(repoRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if scope == "" {
+		scope = cfg.Create.Scope
+	}
+	if templateName == "" && opts.Plan == nil {
+		templateName = cfg.Create.Template
+	}
+
+	// Load the plan template up front so a typo fails before anything is created
+	var planTemplate string
+	if templateName != "" {
+		dirs, err := config.TemplateDirs(repoRoot)
+		if err != nil {
+			return err
+		}
+		planTemplate, err = plan.LoadTemplate(templateName, dirs...)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Collect dependencies of every agent this workspace may run, checking
+	// the configured executable in place of the agent's own binary
+	var agentDeps []deps.Dependency
+	for _, choice := range cfg.Agents(agentName) {
+		agent, err := workspace.LookupAgent(choice.Name)
+		if err != nil {
+			return err
+		}
+		for _, dep := range agent.Dependencies() {
+			if choice.Binary != "" && dep.Name == agent.Name() {
+				dep.Name = choice.Binary
+			}
+			agentDeps = append(agentDeps, dep)
+		}
+	}
 
 	// Validate dependencies before proceeding
-	validation := deps.Validate()
+	validation := deps.Validate(agentDeps...)
 	if !validation.AllRequiredMet {
 		fmt.Print(deps.FormatValidationResult(validation))
 		return fmt.Errorf("cannot create workspace: missing required dependencies")
@@ -68,37 +151,32 @@ func createWorkspace(name, scope, agentCmd string, detach, useMain bool) error {
 		return fmt.Errorf("session %q already exists, use 'planq open %s' to open it", sessionName, name)
 	}
 
+	// Remember the branch the workspace is created from
+	baseBranch, _ := git.GetCurrentBranch()
+
 	var workdir string
 	var isMainWorkspace bool
 	st := stackit.NewClient()
 
-	if useMain {
+	if opts.Main {
 		// Create workspace using main worktree
-		repoRoot, err := git.GetRepoRoot()
-		if err != nil {
-			return fmt.Errorf("failed to get repository root: %w", err)
-		}
-
-		// Check if a main workspace already exists for this repo
-		globalState, err := state.Load()
+		// Check and record the main workspace under the state lock so
+		// concurrent creates can't both claim this repo
+		err := state.Update(func(globalState *state.GlobalState) error {
+			if existing, exists := globalState.GetMainWorkspace(repoRoot); exists {
+				return fmt.Errorf("main workspace %q already exists for this repository; remove it first with 'planq remove %s'", existing.Name, existing.Name)
+			}
+			globalState.SetMainWorkspace(repoRoot, name)
+			return nil
+		})
 		if err != nil {
-			return fmt.Errorf("failed to load global state: %w", err)
-		}
-
-		if existing, exists := globalState.GetMainWorkspace(repoRoot); exists {
-			return fmt.Errorf("main workspace %q already exists for this repository; remove it first with 'planq remove %s'", existing.Name, existing.Name)
+			return err
 		}
 
 		workdir = repoRoot
 		isMainWorkspace = true
 
 		fmt.Printf("  Using main worktree at: %s\n", workdir)
-
-		// Record in global state
-		globalState.SetMainWorkspace(repoRoot, name)
-		if err := globalState.Save(); err != nil {
-			return fmt.Errorf("failed to save global state: %w", err)
-		}
 	} else {
 		// Create worktree via stackit
 		fmt.Printf("  Creating worktree via stackit...\n")
@@ -120,71 +198,134 @@ func createWorkspace(name, scope, agentCmd string, detach, useMain bool) error {
 		Name:         name,
 		WorktreePath: workdir,
 	}
+	configureAgent(cfg, ws, workspace.ModePlan, agentName)
+	ws.InitialPrompt = opts.InitialPrompt
 
-	fmt.Printf("  Initializing .planq directory...\n")
-	if err := ws.InitPlanqDir(); err != nil {
-		// Cleanup on failure
+	// The item is claimed, archived and reopened in the queue it was read from
+	queueRepo := opts.QueueRoot
+
+	// cleanup undoes the worktree, state registrations and queue claim if a later step fails
+	claimed := false
+	cleanup := func() {
 		if !isMainWorkspace {
 			_ = st.WorktreeRemove(name)
-		} else {
-			// Remove state entry for main workspace
-			if globalState, err := state.Load(); err == nil {
+		}
+		if claimed {
+			_, _ = queue.Reopen(queueRepo, opts.QueueItem)
+		}
+		_ = state.Update(func(globalState *state.GlobalState) error {
+			if isMainWorkspace {
 				globalState.RemoveMainWorkspace(workdir)
-				_ = globalState.Save()
 			}
+			globalState.UnregisterWorkspace(workdir)
+			return nil
+		})
+	}
+
+	if opts.QueueItem != 0 {
+		fmt.Printf("  Claiming queue item #%d...\n", opts.QueueItem)
+		// The claim has no lease: it lasts until the workspace is removed
+		if _, err := queue.Claim(queueRepo, opts.QueueItem, name, 0); err != nil {
+			cleanup()
+			return err
 		}
+		claimed = true
+	}
+
+	fmt.Printf("  Initializing .planq directory...\n")
+	planContent := opts.Plan
+	if planContent == nil {
+		planContent, err = renderPlanTemplate(planTemplate, name, workdir, scope)
+	}
+	if err == nil {
+		err = ws.InitPlanqDir(planContent)
+	}
+	meta := newMetadata(cfg, ws, repoRoot, baseBranch, scope, agentName, agentCmd, isMainWorkspace)
+	meta.QueueItem = opts.QueueItem
+	if opts.QueueItem != 0 {
+		meta.QueueRoot = queueRepo
+	}
+	if err == nil {
+		err = ws.WriteMetadata(meta)
+	}
+	if err == nil {
+		err = registerWorkspace(meta)
+	}
+	if err != nil {
+		cleanup()
 		return fmt.Errorf("failed to initialize .planq directory: %w", err)
 	}
 	fmt.Printf("  Plan file will be at: %s\n", ws.PlanFile())
 
 	fmt.Printf("  Initializing .agent directory...\n")
 	if err := ws.InitAgentDir(); err != nil {
-		// Cleanup on failure
-		if !isMainWorkspace {
-			_ = st.WorktreeRemove(name)
-		} else {
-			// Remove state entry for main workspace
-			if globalState, err := state.Load(); err == nil {
-				globalState.RemoveMainWorkspace(workdir)
-				_ = globalState.Save()
-			}
-		}
+		cleanup()
 		return fmt.Errorf("failed to initialize .agent directory: %w", err)
 	}
 
 	// Determine agent command (use workspace default unless overridden)
-	finalAgentCmd := ws.AgentCommand()
-	if agentCmd != "" {
-		finalAgentCmd = agentCmd
+	finalAgentCmd := agentCmd
+	if finalAgentCmd == "" {
+		finalAgentCmd, err = ws.AgentCommand()
+		if err != nil {
+			cleanup()
+			return fmt.Errorf("failed to build agent command: %w", err)
+		}
 	}
 
-	// Create tmux session with layout
-	// Layout: agent (left), plan viewer (top-right), terminal (bottom-right)
 	fmt.Printf("  Creating tmux session %q...\n", sessionName)
-	layout := tmux.Layout{
-		Name: "agent-plan-terminal",
-		Panes: []tmux.PaneSpec{
-			{Name: "agent", Size: 60, Command: finalAgentCmd},
-			{Name: "plan", Size: 20, Command: fmt.Sprintf("glow %s --tui", ws.PlanFile())},
-			{Name: "terminal", Size: 20, Command: ""},
-		},
+	if err := startSession(tm, ws, cfg, finalAgentCmd, agentName); err != nil {
+		cleanup()
+		return fmt.Errorf("failed to create tmux session: %w", err)
 	}
 
-	_, err = tm.CreateSession(sessionName, workdir, layout)
+	if opts.Detach {
+		fmt.Println()
+		fmt.Printf("To open: planq open %s\n", name)
+		return nil
+	}
+
+	// Attach to the session
+	return tm.AttachSession(sessionName)
+}
+
+// startSession creates the tmux session for a workspace using the layout for its
+// current mode, then configures environment, keybindings, status bar and pane titles.
+// workspaceAgent is recorded in the session so later mode switches use the same agent.
+func startSession(tm *tmux.Manager, ws *workspace.Workspace, cfg *config.Config, agentCmd, workspaceAgent string) error {
+	name := ws.Name
+	workdir := ws.WorktreePath
+	sessionName := sessionPrefix + name
+
+	mode, err := ws.GetMode()
 	if err != nil {
-		// Cleanup on failure
-		if !isMainWorkspace {
-			_ = st.WorktreeRemove(name)
-		} else {
-			// Remove state entry for main workspace
-			if globalState, err := state.Load(); err == nil {
-				globalState.RemoveMainWorkspace(workdir)
-				_ = globalState.Save()
-			}
+		mode = workspace.ModePlan
+	}
+
+	layout := modeLayout(cfg, ws, mode, agentCmd)
+	if _, err := tm.CreateSession(sessionName, workdir, layout); err != nil {
+		return err
+	}
+
+	configureSession(tm, ws, mode, workspaceAgent)
+
+	// Set pane titles for the mode layout
+	for i, title := range modePaneTitles(cfg, mode) {
+		if err := tm.SetPaneTitle(sessionName, i, title); err != nil {
+			fmt.Printf("  Warning: failed to set pane %d title: %v\n", i, err)
 		}
-		return fmt.Errorf("failed to create tmux session: %w", err)
 	}
 
+	return nil
+}
+
+// configureSession sets the workspace environment, keybindings, status bar
+// and pane borders on an existing tmux session.
+func configureSession(tm *tmux.Manager, ws *workspace.Workspace, mode workspace.Mode, workspaceAgent string) {
+	name := ws.Name
+	workdir := ws.WorktreePath
+	sessionName := sessionPrefix + name
+
 	// Set PLANQ_WORKSPACE environment variable in the session
 	if err := tm.SetEnvironment(sessionName, "PLANQ_WORKSPACE", name); err != nil {
 		fmt.Printf("  Warning: failed to set PLANQ_WORKSPACE: %v\n", err)
@@ -195,6 +336,16 @@ func createWorkspace(name, scope, agentCmd string, detach, useMain bool) error {
 		fmt.Printf("  Warning: failed to set PLANQ_WORKTREE_PATH: %v\n", err)
 	}
 
+	// Record the workspace agent choice and the agent running in pane 0
+	if workspaceAgent != "" {
+		if err := tm.SetEnvironment(sessionName, "PLANQ_AGENT", workspaceAgent); err != nil {
+			fmt.Printf("  Warning: failed to set PLANQ_AGENT: %v\n", err)
+		}
+	}
+	if err := tm.SetEnvironment(sessionName, "PLANQ_ACTIVE_AGENT", ws.AgentName); err != nil {
+		fmt.Printf("  Warning: failed to set PLANQ_ACTIVE_AGENT: %v\n", err)
+	}
+
 	// Bind mode toggle keybinding (Ctrl-B m)
 	if err := tm.BindModeToggle(sessionName, name, workdir); err != nil {
 		fmt.Printf("  Warning: failed to bind mode toggle key: %v\n", err)
@@ -205,8 +356,8 @@ func createWorkspace(name, scope, agentCmd string, detach, useMain bool) error {
 		fmt.Printf("  Warning: failed to bind workspace navigation keys: %v\n", err)
 	}
 
-	// Configure status bar with initial mode (plan)
-	if err := tm.ConfigureStatusBar(sessionName, name, "plan"); err != nil {
+	// Configure status bar with the current mode
+	if err := tm.ConfigureStatusBar(sessionName, name, workdir, string(mode), modeStatusColor(mode)); err != nil {
 		fmt.Printf("  Warning: failed to configure status bar: %v\n", err)
 	}
 
@@ -214,21 +365,52 @@ func createWorkspace(name, scope, agentCmd string, detach, useMain bool) error {
 	if err := tm.ConfigurePaneBorders(sessionName); err != nil {
 		fmt.Printf("  Warning: failed to configure pane borders: %v\n", err)
 	}
+}
 
-	// Set pane titles for plan mode layout
-	paneTitles := []string{"Agent", "Plan", "Terminal"}
-	for i, title := range paneTitles {
-		if err := tm.SetPaneTitle(sessionName, i, title); err != nil {
-			fmt.Printf("  Warning: failed to set pane %d title: %v\n", i, err)
-		}
+// renderPlanTemplate renders the initial plan for a new workspace.
+// Returns nil (an empty plan) when no template is used.
+func renderPlanTemplate(source, name, workdir, scope string) ([]byte, error) {
+	if source == "" {
+		return nil, nil
 	}
 
-	if detach {
-		fmt.Println()
-		fmt.Printf("To open: planq open %s\n", name)
-		return nil
+	branch, err := git.GetBranchIn(workdir)
+	if err != nil {
+		branch = name // stackit names the branch after the workspace
 	}
 
-	// Attach to the session
-	return tm.AttachSession(sessionName)
+	return plan.RenderTemplate(source, plan.TemplateData{
+		Name:   name,
+		Branch: branch,
+		Scope:  scope,
+		Date:   time.Now().Format("2006-01-02"),
+	})
+}
+
+// newMetadata describes a newly created workspace.
+func newMetadata(cfg *config.Config, ws *workspace.Workspace, repoRoot, baseBranch, scope, agentName, agentCmd string, isMain bool) *workspace.Metadata {
+	meta := &workspace.Metadata{
+		Name:         ws.Name,
+		Repo:         repoRoot,
+		WorktreePath: ws.WorktreePath,
+		BaseBranch:   baseBranch,
+		Scope:        scope,
+		Agent:        agentName,
+		AgentCommand: agentCmd,
+		Layout:       modeLayout(cfg, ws, workspace.ModePlan, "").Name,
+		Main:         isMain,
+		CreatedAt:    time.Now(),
+	}
+
+	if repo, err := git.GetMainRepoRoot(repoRoot); err == nil {
+		meta.Repo = repo
+	}
+	if branch, err := git.GetBranchIn(ws.WorktreePath); err == nil {
+		meta.Branch = branch
+	}
+	if parent := os.Getenv("PLANQ_WORKSPACE"); parent != ws.Name {
+		meta.Parent = parent
+	}
+
+	return meta
 }
@@ -0,0 +1,251 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"planq.dev/planq/internal/git"
+	"planq.dev/planq/internal/stackit"
+	"planq.dev/planq/internal/state"
+	"planq.dev/planq/internal/workspace"
+)
+
+// findWorkspace locates a workspace by name and loads its metadata. The
+// --worktree flag is checked first, then the global registry, then the
+// worktrees stackit knows, then the workspace's tmux session; the first
+// directory whose workspace.json names the workspace wins. Later sources are
+// only consulted when earlier ones miss, and unreadable metadata is skipped.
+//
+// Workspaces from before workspace.json existed are found by their plan file
+// and get metadata derived from the worktree; 'planq doctor --fix' saves it.
+func findWorkspace(name string) (*workspace.Workspace, *workspace.Metadata, error) {
+	sources := []func() []string{
+		func() []string {
+			if modeWorktree != "" {
+				return []string{modeWorktree}
+			}
+			return nil
+		},
+		func() []string { return registryCandidates(name) },
+		func() []string {
+			if path, err := stackit.NewClient().WorktreeOpen(name); err == nil {
+				return []string{path}
+			}
+			return nil
+		},
+		func() []string {
+			if path, err := getTmuxSessionEnv(sessionPrefix+name, "PLANQ_WORKTREE_PATH"); err == nil && path != "" {
+				return []string{path}
+			}
+			return nil
+		},
+	}
+
+	var legacy *workspace.Workspace
+	var readErr error
+	for _, source := range sources {
+		for _, dir := range source() {
+			meta, err := workspace.ReadMetadata(dir)
+			if err != nil {
+				if readErr == nil {
+					readErr = err
+				}
+				continue
+			}
+			if meta != nil && meta.Name == name {
+				return &workspace.Workspace{Name: name, WorktreePath: dir}, meta, nil
+			}
+			if meta == nil && legacy == nil {
+				ws := &workspace.Workspace{Name: name, WorktreePath: dir}
+				if _, err := os.Stat(ws.PlanFile()); err == nil {
+					legacy = ws
+				}
+			}
+		}
+	}
+
+	if legacy != nil {
+		return legacy, legacyMetadata(legacy), nil
+	}
+	if readErr != nil {
+		return nil, nil, fmt.Errorf("workspace %q not found: %w", name, readErr)
+	}
+	return nil, nil, fmt.Errorf("workspace %q not found", name)
+}
+
+// registryCandidates returns the registered directories of the named
+// workspace. When several repositories have a workspace of that name, the
+// current repository's comes first.
+func registryCandidates(name string) []string {
+	globalState, err := state.Load()
+	if err != nil {
+		return nil
+	}
+
+	entries := globalState.FindWorkspacesByName(name)
+	if len(entries) > 1 {
+		if repo := currentRepo(); repo != "" {
+			sort.SliceStable(entries, func(i, j int) bool {
+				return samePath(entries[i].RepoPath, repo) && !samePath(entries[j].RepoPath, repo)
+			})
+		}
+	}
+
+	var candidates []string
+	for _, entry := range entries {
+		candidates = append(candidates, entry.WorktreePath)
+	}
+	if repoPath, exists := globalState.FindMainWorkspaceByName(name); exists {
+		candidates = append(candidates, repoPath)
+	}
+	return candidates
+}
+
+// registerWorkspace records a workspace in the global registry.
+func registerWorkspace(meta *workspace.Metadata) error {
+	return state.Update(func(globalState *state.GlobalState) error {
+		globalState.RegisterWorkspace(registryEntry(meta))
+		return nil
+	})
+}
+
+// registryEntry returns the global registry entry for a workspace.
+func registryEntry(meta *workspace.Metadata) state.WorkspaceEntry {
+	return state.WorkspaceEntry{
+		Name:         meta.Name,
+		RepoPath:     meta.Repo,
+		WorktreePath: meta.WorktreePath,
+		SessionName:  sessionPrefix + meta.Name,
+	}
+}
+
+// currentRepo returns the main repository root of the current directory, or "".
+func currentRepo() string {
+	root, err := git.GetRepoRoot()
+	if err != nil {
+		return ""
+	}
+	if repo, err := git.GetMainRepoRoot(root); err == nil {
+		return repo
+	}
+	return root
+}
+
+// samePath reports whether two paths refer to the same directory.
+func samePath(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ra, errA := filepath.EvalSymlinks(a)
+	rb, errB := filepath.EvalSymlinks(b)
+	return errA == nil && errB == nil && ra == rb
+}
+
+// workspaceLocation is where a known workspace lives on disk.
+type workspaceLocation struct {
+	Name   string
+	Path   string
+	Repo   string
+	Branch string
+	IsMain bool
+}
+
+// knownWorkspaces returns every workspace that exists on disk, keyed by
+// worktree path, across all repositories. Names come from workspace.json when
+// present. Workspaces missing from the registry are listed but not
+// registered; `planq doctor --fix` does that.
+func knownWorkspaces(sessions map[string]bool) map[string]workspaceLocation {
+	known := make(map[string]workspaceLocation)
+	add := func(loc workspaceLocation) {
+		key := pathKey(loc.Path)
+		if _, exists := known[key]; !exists {
+			known[key] = loc
+		}
+	}
+
+	globalState, err := state.Load()
+	if err != nil {
+		globalState = &state.GlobalState{}
+	}
+
+	// Worktrees created by stackit in the current repo
+	repo := currentRepo()
+	st := stackit.NewClient()
+	if worktrees, err := st.WorktreeList(); err == nil {
+		for _, wt := range worktrees {
+			loc := workspaceLocation{Name: wt.Name, Path: wt.Path, Repo: repo, Branch: wt.Branch}
+			if meta, err := workspace.ReadMetadata(wt.Path); err == nil && meta != nil {
+				loc.Name, loc.IsMain = meta.Name, meta.Main
+			}
+			add(loc)
+		}
+	}
+
+	// Registered workspaces in every repo
+	for _, entry := range globalState.Workspaces {
+		meta, err := workspace.ReadMetadata(entry.WorktreePath)
+		if err != nil || meta == nil || meta.Name != entry.Name {
+			continue // worktree is gone or now holds another workspace
+		}
+		add(workspaceLocation{
+			Name:   entry.Name,
+			Path:   entry.WorktreePath,
+			Repo:   entry.RepoPath,
+			Branch: branchIn(entry.WorktreePath),
+			IsMain: meta.Main,
+		})
+	}
+
+	// Main workspaces recorded in global state
+	for repoPath, entry := range globalState.MainWorkspaces {
+		name := entry.Name
+		if meta, err := workspace.ReadMetadata(repoPath); err == nil && meta != nil {
+			name = meta.Name
+		}
+		add(workspaceLocation{Name: name, Path: repoPath, Repo: repoPath, Branch: branchIn(repoPath), IsMain: true})
+	}
+
+	// Sessions whose recorded worktree holds matching metadata
+	names := workspaceNames(known)
+	for name := range sessions {
+		if names[name] {
+			continue
+		}
+		path, err := getTmuxSessionEnv(sessionPrefix+name, "PLANQ_WORKTREE_PATH")
+		if err != nil || path == "" {
+			continue
+		}
+		if meta, err := workspace.ReadMetadata(path); err == nil && meta != nil && meta.Name == name {
+			add(workspaceLocation{Name: name, Path: path, Repo: meta.Repo, Branch: branchIn(path), IsMain: meta.Main})
+		}
+	}
+
+	return known
+}
+
+// workspaceNames returns the set of names of the given workspaces.
+func workspaceNames(known map[string]workspaceLocation) map[string]bool {
+	names := make(map[string]bool, len(known))
+	for _, loc := range known {
+		names[loc.Name] = true
+	}
+	return names
+}
+
+// pathKey returns a key under which equivalent paths compare equal.
+func pathKey(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
+// branchIn returns the branch checked out in dir, or "-" if unknown.
+func branchIn(dir string) string {
+	if branch, err := git.GetBranchIn(dir); err == nil {
+		return branch
+	}
+	return "-"
+}
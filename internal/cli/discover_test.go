@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"planq.dev/planq/internal/state"
+	"planq.dev/planq/internal/workspace"
+)
+
+func TestFindWorkspaceSkipsUnreadableMetadata(t *testing.T) {
+	t.Setenv(state.HomeEnv, t.TempDir())
+	root := t.TempDir()
+
+	// The registry lists the broken directory first
+	broken := &workspace.Workspace{Name: "fix", WorktreePath: filepath.Join(root, "a-broken")}
+	good := &workspace.Workspace{Name: "fix", WorktreePath: filepath.Join(root, "b-good")}
+	if err := os.MkdirAll(broken.PlanqDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(broken.MetadataFile(), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(good.PlanqDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := good.WriteMetadata(&workspace.Metadata{Name: "fix", Repo: root, WorktreePath: good.WorktreePath}); err != nil {
+		t.Fatal(err)
+	}
+	for _, ws := range []*workspace.Workspace{broken, good} {
+		if err := registerWorkspace(&workspace.Metadata{Name: "fix", Repo: root, WorktreePath: ws.WorktreePath}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ws, meta, err := findWorkspace("fix")
+	if err != nil {
+		t.Fatalf("findWorkspace() error = %v", err)
+	}
+	if ws.WorktreePath != good.WorktreePath || meta.Name != "fix" {
+		t.Errorf("findWorkspace() = %s, %+v; want %s", ws.WorktreePath, meta, good.WorktreePath)
+	}
+}
+
+func TestFindWorkspaceWithoutMetadata(t *testing.T) {
+	t.Setenv(state.HomeEnv, t.TempDir())
+
+	// A workspace from before workspace.json has only its plan file
+	legacy := &workspace.Workspace{Name: "old", WorktreePath: t.TempDir()}
+	if err := legacy.InitPlanqDir([]byte("# Plan\n")); err != nil {
+		t.Fatal(err)
+	}
+	modeWorktree = legacy.WorktreePath
+	t.Cleanup(func() { modeWorktree = "" })
+
+	ws, meta, err := findWorkspace("old")
+	if err != nil {
+		t.Fatalf("findWorkspace() error = %v", err)
+	}
+	if ws.WorktreePath != legacy.WorktreePath || meta.Name != "old" {
+		t.Errorf("findWorkspace() = %s, %+v", ws.WorktreePath, meta)
+	}
+	if _, err := os.Stat(legacy.MetadataFile()); !os.IsNotExist(err) {
+		t.Errorf("findWorkspace() wrote workspace.json: %v", err)
+	}
+}
@@ -0,0 +1,250 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"planq.dev/planq/internal/queue"
+	"planq.dev/planq/internal/tmux"
+	"planq.dev/planq/internal/workspace"
+)
+
+var (
+	dispatchMaxParallel int
+	dispatchInterval    time.Duration
+	dispatchTags        []string
+	dispatchAgent       string
+)
+
+var dispatchCmd = &cobra.Command{
+	Use:   "dispatch",
+	Short: "Work through the queue with a fixed number of agents",
+	Long: `Start workspaces for queued items, most urgent first, keeping at most
+--max-parallel of them busy.
+
+Each workspace is created detached with the item as the agent's first message
+and claims the item. A workspace's slot frees up when its agent stops (the
+review flag set by 'planq notify stopped'), its session ends or its item is
+no longer claimed, and the next open item is started.
+
+Dispatch ends when the queue is empty and every agent has stopped, or on
+Ctrl-C. Workspaces are left running either way; a summary is printed at the end.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return runDispatch(ctx)
+	},
+}
+
+func init() {
+	dispatchCmd.Flags().IntVarP(&dispatchMaxParallel, "max-parallel", "j", 3, "Maximum number of agents working at once")
+	dispatchCmd.Flags().DurationVar(&dispatchInterval, "interval", 5*time.Second, "How often to check on running agents")
+	dispatchCmd.Flags().StringSliceVarP(&dispatchTags, "tag", "t", nil, "Only dispatch items with this tag (repeatable)")
+	dispatchCmd.Flags().StringVar(&dispatchAgent, "agent", "", "Agent backend (default: agent.name from config)")
+}
+
+// dispatchJob is a queue item the dispatcher started a workspace for.
+type dispatchJob struct {
+	item    queue.Item
+	name    string
+	ws      *workspace.Workspace
+	outcome string
+	err     error
+}
+
+// dispatcher keeps up to max workspaces busy with queue items.
+type dispatcher struct {
+	projectRoot string
+	tm          *tmux.Manager
+	max         int
+
+	running []*dispatchJob
+	jobs    []*dispatchJob
+	tried   map[int]bool
+}
+
+// runDispatch starts and watches workspaces until the queue is drained or ctx ends.
+func runDispatch(ctx context.Context) error {
+	if dispatchMaxParallel < 1 {
+		return fmt.Errorf("--max-parallel must be at least 1")
+	}
+	if dispatchInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	projectRoot, err := queueRoot()
+	if err != nil {
+		return err
+	}
+	tm, err := tmux.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize tmux: %w", err)
+	}
+
+	d := &dispatcher{
+		projectRoot: projectRoot,
+		tm:          tm,
+		max:         dispatchMaxParallel,
+		tried:       make(map[int]bool),
+	}
+
+	fmt.Printf("Dispatching queue items, up to %d at a time (Ctrl-C to stop)...\n", d.max)
+
+	ticker := time.NewTicker(dispatchInterval)
+	defer ticker.Stop()
+	for {
+		d.reap()
+		drained, err := d.fill(ctx)
+		if err != nil {
+			d.printSummary()
+			return err
+		}
+		if drained && len(d.running) == 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println("\nStopping dispatch, running workspaces are left open")
+			d.printSummary()
+			return nil
+		case <-ticker.C:
+		}
+	}
+
+	d.printSummary()
+	return nil
+}
+
+// fill starts workspaces for open items until every slot is busy.
+// It reports whether the queue has no items left to start.
+func (d *dispatcher) fill(ctx context.Context) (bool, error) {
+	for len(d.running) < d.max {
+		if ctx.Err() != nil {
+			return false, nil
+		}
+
+		items, err := queue.List(d.projectRoot, queue.Filter{Status: queue.StatusOpen, Tags: dispatchTags})
+		if err != nil {
+			return false, err
+		}
+
+		var next *queue.Item
+		for i := range items {
+			if !d.tried[items[i].ID] {
+				next = &items[i]
+				break
+			}
+		}
+		if next == nil {
+			return true, nil
+		}
+
+		d.tried[next.ID] = true
+		d.start(next)
+	}
+	return false, nil
+}
+
+// start creates a detached workspace for item.
+func (d *dispatcher) start(item *queue.Item) {
+	job := &dispatchJob{item: *item, name: d.workspaceName(item)}
+	d.jobs = append(d.jobs, job)
+
+	fmt.Printf("\n[dispatch] #%d %s -> %s\n", item.ID, item.Title, job.name)
+	err := createWorkspace(job.name, createOptions{
+		Agent:         dispatchAgent,
+		Detach:        true,
+		InitialPrompt: queueItemPrompt(item),
+		QueueItem:     item.ID,
+		QueueRoot:     d.projectRoot,
+	})
+	if err != nil {
+		job.outcome, job.err = "failed", err
+		fmt.Printf("[dispatch] #%d failed: %v\n", item.ID, err)
+		return
+	}
+
+	ws, _, err := findWorkspace(job.name)
+	if err != nil {
+		job.outcome, job.err = "lost", err
+		fmt.Printf("[dispatch] #%d started but its workspace was not found: %v\n", item.ID, err)
+		return
+	}
+	job.ws = ws
+	d.running = append(d.running, job)
+}
+
+// workspaceName picks an unused workspace name for item.
+func (d *dispatcher) workspaceName(item *queue.Item) string {
+	name := workspaceNameFor(item)
+	if d.nameTaken(name) {
+		name = fmt.Sprintf("%s-%d", name, item.ID)
+	}
+	return name
+}
+
+// nameTaken reports whether a workspace or session already uses name.
+func (d *dispatcher) nameTaken(name string) bool {
+	if _, _, err := findWorkspace(name); err == nil {
+		return true
+	}
+	exists, err := d.tm.SessionExists(sessionPrefix + name)
+	return err != nil || exists
+}
+
+// reap frees the slots of workspaces whose agent is no longer working.
+func (d *dispatcher) reap() {
+	var running []*dispatchJob
+	for _, job := range d.running {
+		if outcome := d.finished(job); outcome != "" {
+			job.outcome = outcome
+			fmt.Printf("[dispatch] #%d %s: %s\n", job.item.ID, job.name, outcome)
+			continue
+		}
+		running = append(running, job)
+	}
+	d.running = running
+}
+
+// finished describes why a job's agent stopped working, or returns "" if it
+// is still busy.
+func (d *dispatcher) finished(job *dispatchJob) string {
+	if review, err := job.ws.GetReviewState(); err == nil && review.NeedsReview {
+		return "stopped, needs review"
+	}
+	if exists, err := d.tm.SessionExists(sessionPrefix + job.name); err == nil && !exists {
+		return "session ended"
+	}
+	if item, err := queue.Get(d.projectRoot, job.item.ID); err == nil && (item.Status != queue.StatusClaimed || item.Workspace != job.name) {
+		return "item " + string(item.Status)
+	}
+	return ""
+}
+
+// printSummary lists what happened to every dispatched item.
+func (d *dispatcher) printSummary() {
+	fmt.Println()
+	if len(d.jobs) == 0 {
+		fmt.Println("No queue items were dispatched")
+		return
+	}
+
+	fmt.Printf("Dispatched %d item(s):\n", len(d.jobs))
+	for _, job := range d.jobs {
+		outcome := job.outcome
+		if outcome == "" {
+			outcome = "running"
+		}
+		if job.err != nil {
+			outcome += ": " + job.err.Error()
+		}
+		fmt.Printf("  #%-4d %-40s %s\n", job.item.ID, job.name, outcome)
+	}
+}
@@ -0,0 +1,302 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"planq.dev/planq/internal/git"
+	"planq.dev/planq/internal/stackit"
+	"planq.dev/planq/internal/state"
+	"planq.dev/planq/internal/tmux"
+	"planq.dev/planq/internal/workspace"
+)
+
+var doctorFix bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check workspaces for inconsistencies",
+	Long: `Check that stackit worktrees, tmux sessions, .planq directories and the
+global state agree with each other, and print a diagnosis per workspace.
+
+Checks:
+  - worktrees without a .planq directory or workspace.json
+  - workspaces missing from the global registry
+  - main workspace and registry entries whose directory was deleted
+  - tmux sessions whose worktree was removed or that have no workspace
+  - outdated agent skill files (e.g. .claude/commands/planq-mode.md)
+
+Use --fix to repair every issue found.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDoctor(doctorFix)
+	},
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Repair the issues found")
+}
+
+// doctorIssue is a single inconsistency found by planq doctor.
+type doctorIssue struct {
+	Problem string
+	Fix     func() error
+}
+
+// doctorReport collects issues per workspace name.
+type doctorReport map[string][]doctorIssue
+
+// add records an issue for a workspace.
+func (r doctorReport) add(name, problem string, fix func() error) {
+	r[name] = append(r[name], doctorIssue{Problem: problem, Fix: fix})
+}
+
+// runDoctor checks all workspaces and optionally repairs them.
+func runDoctor(fix bool) error {
+	globalState, err := state.Load()
+	if err != nil {
+		return err
+	}
+
+	report := make(doctorReport)
+
+	// Directories that may hold a workspace, mapped to the workspace name
+	dirs := make(map[string]string)
+	st := stackit.NewClient()
+	if worktrees, err := st.WorktreeList(); err == nil {
+		for _, wt := range worktrees {
+			dirs[wt.Path] = wt.Name
+		}
+	}
+	for path, entry := range globalState.Workspaces {
+		if !dirExists(path) {
+			report.add(entry.Name, fmt.Sprintf("registered worktree %s no longer exists", path), func() error {
+				return state.Update(func(s *state.GlobalState) error {
+					s.UnregisterWorkspace(path)
+					return nil
+				})
+			})
+			continue
+		}
+		if _, exists := dirs[path]; !exists {
+			dirs[path] = entry.Name
+		}
+	}
+	for repoPath, entry := range globalState.MainWorkspaces {
+		if !dirExists(repoPath) {
+			report.add(entry.Name, fmt.Sprintf("main workspace repo %s no longer exists", repoPath), func() error {
+				return state.Update(func(s *state.GlobalState) error {
+					s.RemoveMainWorkspace(repoPath)
+					s.UnregisterWorkspace(repoPath)
+					return nil
+				})
+			})
+			continue
+		}
+		if _, exists := dirs[repoPath]; !exists {
+			dirs[repoPath] = entry.Name
+		}
+	}
+
+	names := make(map[string]bool)
+	for path, name := range dirs {
+		names[checkWorkspaceDir(report, globalState, name, path)] = true
+	}
+
+	checkSessions(report, names)
+
+	return printDoctorReport(report, names, fix)
+}
+
+// checkWorkspaceDir checks the workspace in dir and returns its name.
+func checkWorkspaceDir(report doctorReport, globalState *state.GlobalState, name, dir string) string {
+	ws := &workspace.Workspace{Name: name, WorktreePath: dir}
+
+	if !dirExists(ws.PlanqDir()) {
+		report.add(name, fmt.Sprintf("%s has no .planq directory", dir), func() error {
+			if err := ws.InitPlanqDir(nil); err != nil {
+				return err
+			}
+			_, err := backfillMetadata(ws)
+			return err
+		})
+		return name
+	}
+
+	meta, err := workspace.ReadMetadata(dir)
+	if err != nil {
+		report.add(name, err.Error(), nil)
+		return name
+	}
+	if meta == nil {
+		report.add(name, fmt.Sprintf("%s has no workspace.json", dir), func() error {
+			_, err := backfillMetadata(ws)
+			return err
+		})
+		return name
+	}
+
+	name = meta.Name
+	ws.Name = name
+	ws.AgentName = meta.Agent
+
+	if _, registered := globalState.Workspaces[dir]; !registered {
+		report.add(name, "not in the global workspace registry", func() error {
+			return registerWorkspace(meta)
+		})
+	}
+
+	agent, err := ws.Agent()
+	if err != nil {
+		report.add(name, err.Error(), nil)
+		return name
+	}
+	if outdated, err := agent.Outdated(ws); err != nil {
+		report.add(name, err.Error(), nil)
+	} else if outdated {
+		report.add(name, fmt.Sprintf("%s skill files are outdated", agent.Name()), ws.InstallAgent)
+	}
+
+	return name
+}
+
+// backfillMetadata writes workspace.json for a workspace that predates it
+// and registers the workspace.
+func backfillMetadata(ws *workspace.Workspace) (*workspace.Metadata, error) {
+	meta := legacyMetadata(ws)
+	if err := ws.WriteMetadata(meta); err != nil {
+		return nil, err
+	}
+	if err := registerWorkspace(meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// legacyMetadata derives the metadata of a workspace that predates
+// workspace.json, recovering what it can from git, tmux and global state.
+func legacyMetadata(ws *workspace.Workspace) *workspace.Metadata {
+	meta := &workspace.Metadata{
+		Name:         ws.Name,
+		Repo:         ws.WorktreePath,
+		WorktreePath: ws.WorktreePath,
+		CreatedAt:    time.Now(), // the real creation time was not recorded
+	}
+
+	if repo, err := git.GetMainRepoRoot(ws.WorktreePath); err == nil {
+		meta.Repo = repo
+	}
+	if branch, err := git.GetBranchIn(ws.WorktreePath); err == nil {
+		meta.Branch = branch
+	}
+	if agent, err := getTmuxSessionEnv(sessionPrefix+ws.Name, "PLANQ_AGENT"); err == nil {
+		meta.Agent = agent
+	}
+	if globalState, err := state.Load(); err == nil {
+		if repoPath, exists := globalState.FindMainWorkspaceByName(ws.Name); exists && samePath(repoPath, ws.WorktreePath) {
+			meta.Main = true
+		}
+	}
+	if mode, err := ws.GetMode(); err == nil {
+		meta.Layout = string(mode)
+	}
+	return meta
+}
+
+// checkSessions reports planq tmux sessions without a workspace.
+func checkSessions(report doctorReport, names map[string]bool) {
+	tm, err := tmux.NewManager()
+	if err != nil {
+		return
+	}
+	sessions, err := tm.ListSessions(sessionPrefix)
+	if err != nil {
+		return
+	}
+
+	for _, s := range sessions {
+		sessionName := s.Name
+		name := strings.TrimPrefix(sessionName, sessionPrefix)
+		kill := func() error { return tm.KillSession(sessionName) }
+
+		path, _ := getTmuxSessionEnv(sessionName, "PLANQ_WORKTREE_PATH")
+		switch {
+		case path != "" && !dirExists(path):
+			report.add(name, fmt.Sprintf("session %s points at removed worktree %s", sessionName, path), kill)
+		case !names[name]:
+			if meta, err := workspace.ReadMetadata(path); path != "" && err == nil && meta != nil && meta.Name == name {
+				report.add(name, fmt.Sprintf("%s is not in the global workspace registry", path), func() error {
+					return registerWorkspace(meta)
+				})
+				continue
+			}
+			report.add(name, fmt.Sprintf("session %s has no workspace", sessionName), kill)
+		}
+	}
+}
+
+// printDoctorReport prints the diagnosis per workspace, applying fixes if requested.
+func printDoctorReport(report doctorReport, names map[string]bool, fix bool) error {
+	for name := range report {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	if len(sorted) == 0 {
+		fmt.Println("No workspaces found")
+		return nil
+	}
+
+	var found, fixed int
+	for _, name := range sorted {
+		issues := report[name]
+		if len(issues) == 0 {
+			fmt.Printf("✓ %s\n", name)
+			continue
+		}
+
+		fmt.Printf("✗ %s\n", name)
+		for _, issue := range issues {
+			found++
+			fmt.Printf("    - %s\n", issue.Problem)
+			if !fix {
+				continue
+			}
+			if issue.Fix == nil {
+				fmt.Println("      cannot be fixed automatically")
+				continue
+			}
+			if err := issue.Fix(); err != nil {
+				fmt.Printf("      fix failed: %v\n", err)
+				continue
+			}
+			fixed++
+			fmt.Println("      fixed")
+		}
+	}
+
+	fmt.Println()
+	switch {
+	case found == 0:
+		fmt.Println("No issues found")
+	case fix:
+		fmt.Printf("Fixed %d of %d issue(s)\n", fixed, found)
+	default:
+		fmt.Printf("Found %d issue(s). Run 'planq doctor --fix' to repair them.\n", found)
+	}
+	return nil
+}
+
+// dirExists reports whether path is an existing directory.
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -36,6 +36,7 @@ WORKSPACE SWITCHING
   Ctrl+B n          Switch to next workspace
   Ctrl+B p          Switch to previous workspace
   planq list        Show all workspaces
+  planq list --all-repos  Show workspaces of every repository
 
 PANE NAVIGATION
   Ctrl+B ←/→/↑/↓    Move between panes
@@ -46,7 +47,14 @@ MODE SWITCHING
   Ctrl+B m          Toggle plan/execute mode
   planq mode        Show current mode
   planq mode plan   Switch to plan mode
-  planq mode exec   Switch to execute mode
+  planq mode execute Switch to execute mode
+  planq mode review Switch to review mode
+  planq mode test   Switch to test mode
+  planq mode history Show past mode switches
+  planq plan approve Approve the plan (required for execute)
+  planq plan log    List saved plan revisions
+  planq plan diff   Diff the latest revision with the plan
+  planq plan progress Show checklist progress
 
 PANE MANAGEMENT
   Ctrl+B z          Zoom current pane (toggle fullscreen)
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"planq.dev/planq/internal/knowledge"
+)
+
+var (
+	knowledgeWorkspace string
+	knowledgeShort     bool
+)
+
+var decisionsCmd = &cobra.Command{
+	Use:   "decisions [pattern]",
+	Short: "Browse the decisions agents recorded",
+	Long: `Show the decisions agents recorded with the planq_decide MCP tool, oldest first.
+
+Decisions are kept in .planq/decisions/ of the main repository, so they
+survive workspace removal. Each workspace also keeps its own copy in
+.planq/agent/decisions/.
+
+The optional pattern is a case-insensitive regular expression matched against
+the decision, its alternatives, its rationale and the workspace name.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showKnowledge(knowledge.KindDecision, args)
+	},
+}
+
+var learningsCmd = &cobra.Command{
+	Use:   "learnings [pattern]",
+	Short: "Browse the learnings agents recorded",
+	Long: `Show the learnings agents recorded with the planq_learn MCP tool, oldest first.
+
+Learnings are kept in .planq/learnings/ of the main repository, so they
+survive workspace removal. Each workspace also keeps its own copy in
+.planq/agent/learnings/.
+
+The optional pattern is a case-insensitive regular expression matched against
+the insight, its context and the workspace name.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showKnowledge(knowledge.KindLearning, args)
+	},
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{decisionsCmd, learningsCmd} {
+		cmd.Flags().StringVarP(&knowledgeWorkspace, "workspace", "w", "", "Only show records from this workspace")
+		cmd.Flags().BoolVarP(&knowledgeShort, "short", "s", false, "Show one line per record")
+	}
+}
+
+// showKnowledge prints the repository's records of kind that match the
+// pattern in args.
+func showKnowledge(kind knowledge.Kind, args []string) error {
+	var pattern *regexp.Regexp
+	if len(args) > 0 {
+		p, err := regexp.Compile("(?i)" + args[0])
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		pattern = p
+	}
+
+	dir, err := knowledgeRepoDir(kind)
+	if err != nil {
+		return err
+	}
+	records, err := knowledge.List(dir, kind)
+	if err != nil {
+		return err
+	}
+
+	records = knowledge.Search(records, pattern)
+	if knowledgeWorkspace != "" {
+		var filtered []knowledge.Record
+		for _, r := range records {
+			if r.Workspace == knowledgeWorkspace {
+				filtered = append(filtered, r)
+			}
+		}
+		records = filtered
+	}
+
+	if len(records) == 0 {
+		fmt.Printf("No %ss found\n", kind)
+		return nil
+	}
+	for i, r := range records {
+		if i > 0 && !knowledgeShort {
+			fmt.Println()
+		}
+		printKnowledge(r)
+	}
+	return nil
+}
+
+// printKnowledge prints a record's summary on one line, followed by its
+// alternatives and detail unless --short is set.
+func printKnowledge(r knowledge.Record) {
+	prefix := fmt.Sprintf("%s  %-20s", r.Time.Local().Format("2006-01-02 15:04:05"), r.Workspace)
+	fmt.Printf("%s  %s\n", prefix, r.Summary)
+	if knowledgeShort {
+		return
+	}
+
+	indent := strings.Repeat(" ", len(prefix)+2)
+	if len(r.Alternatives) > 0 {
+		fmt.Printf("%sAlternatives: %s\n", indent, strings.Join(r.Alternatives, "; "))
+	}
+	if r.Detail == "" {
+		return
+	}
+	for _, line := range strings.Split(r.Detail, "\n") {
+		if strings.TrimSpace(line) == "" {
+			fmt.Println()
+			continue
+		}
+		fmt.Printf("%s%s\n", indent, line)
+	}
+}
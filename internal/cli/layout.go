@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"strings"
+
+	"planq.dev/planq/internal/config"
+	"planq.dev/planq/internal/tmux"
+	"planq.dev/planq/internal/workspace"
+)
+
+// configureAgent sets the workspace agent backend and executable for a mode.
+// workspaceAgent is the agent chosen for the workspace (empty for the config default).
+func configureAgent(cfg *config.Config, ws *workspace.Workspace, mode workspace.Mode, workspaceAgent string) {
+	ws.AgentName, ws.AgentBinary = cfg.AgentFor(string(mode), workspaceAgent)
+}
+
+// modeLayout returns the tmux layout for a mode, using the config override if present.
+func modeLayout(cfg *config.Config, ws *workspace.Workspace, mode workspace.Mode, agentCmd string) tmux.Layout {
+	if lc, ok := cfg.Layouts[string(mode)]; ok {
+		layout := tmux.Layout{
+			Name:        string(mode),
+			Description: "Configured layout for " + string(mode) + " mode",
+		}
+		for i, pane := range lc.Panes {
+			command := config.ExpandCommand(pane.Command, ws.PlanFile(), ws.Name, ws.WorktreePath)
+			if i == 0 {
+				command = agentCmd
+			}
+			layout.Panes = append(layout.Panes, tmux.PaneSpec{
+				Name:    pane.Name,
+				Size:    pane.Size,
+				Command: command,
+			})
+		}
+		return layout
+	}
+
+	spec, err := workspace.LookupMode(mode)
+	if err != nil {
+		spec, _ = workspace.LookupMode(workspace.ModePlan)
+	}
+	return spec.Layout(ws, agentCmd)
+}
+
+// modePaneTitles returns the pane border titles for a mode.
+func modePaneTitles(cfg *config.Config, mode workspace.Mode) []string {
+	if lc, ok := cfg.Layouts[string(mode)]; ok {
+		titles := make([]string, 0, len(lc.Panes))
+		for _, pane := range lc.Panes {
+			title := pane.Title
+			if title == "" {
+				title = strings.ToUpper(pane.Name[:1]) + pane.Name[1:]
+			}
+			titles = append(titles, title)
+		}
+		return titles
+	}
+
+	spec, err := workspace.LookupMode(mode)
+	if err != nil {
+		return nil
+	}
+	return spec.PaneTitles
+}
+
+// modeStatusColor returns the status bar color for a mode.
+func modeStatusColor(mode workspace.Mode) string {
+	spec, err := workspace.LookupMode(mode)
+	if err != nil {
+		return ""
+	}
+	return spec.StatusColor
+}
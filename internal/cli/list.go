@@ -8,8 +8,7 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
-	"planq.dev/planq/internal/stackit"
-	"planq.dev/planq/internal/state"
+	"planq.dev/planq/internal/plan"
 	"planq.dev/planq/internal/tmux"
 	"planq.dev/planq/internal/workspace"
 )
@@ -84,39 +83,41 @@ var (
 			Foreground(colorMuted)
 )
 
+var listAllRepos bool
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all workspaces",
-	Long:  `List all planq workspaces (tmux sessions and git worktrees).`,
-	Args:  cobra.NoArgs,
+	Long: `List all planq workspaces (tmux sessions and git worktrees).
+
+By default only workspaces of the current repository are shown.
+Use --all-repos to list workspaces across every repository.`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return listWorkspaces()
+		return listWorkspaces(listAllRepos)
 	},
 }
 
+func init() {
+	listCmd.Flags().BoolVar(&listAllRepos, "all-repos", false, "List workspaces of every repository")
+}
+
 // workspaceEntry represents a combined workspace entry for display.
 type workspaceEntry struct {
 	Name        string
+	Repo        string
 	Branch      string
 	Dir         string
 	Status      string
 	Mode        string
+	PlanStatus  string
+	Progress    string
 	IsMain      bool
 	NeedsReview bool
 }
 
-// listWorkspaces lists all planq workspaces with styled cards.
-func listWorkspaces() error {
-	// Collect worktrees
-	worktreeMap := make(map[string]stackit.WorktreeEntry)
-	st := stackit.NewClient()
-	worktrees, err := st.WorktreeList()
-	if err == nil {
-		for _, wt := range worktrees {
-			worktreeMap[wt.Name] = wt
-		}
-	}
-
+// listWorkspaces lists planq workspaces with styled cards.
+func listWorkspaces(allRepos bool) error {
 	// Collect tmux sessions
 	sessionMap := make(map[string]bool)
 	tm, err := tmux.NewManager()
@@ -134,14 +135,31 @@ func listWorkspaces() error {
 		}
 	}
 
-	// Load main workspace info
-	mainWorkspaceNames := make(map[string]bool)
-	if globalState, err := state.Load(); err == nil {
-		mainWorkspaceNames = globalState.GetMainWorkspaceNames()
+	// Build unified list from the workspaces found on disk
+	known := knownWorkspaces(sessionMap)
+	if repo := currentRepo(); !allRepos && repo != "" {
+		// Hide other repositories' workspaces (and their sessions)
+		hidden := make(map[string]bool)
+		for path, loc := range known {
+			if !samePath(loc.Repo, repo) {
+				delete(known, path)
+				hidden[loc.Name] = true
+			}
+		}
+		visible := workspaceNames(known)
+		for name := range hidden {
+			if !visible[name] {
+				delete(sessionMap, name)
+			}
+		}
+	}
+	entries := buildWorkspaceEntries(known, sessionMap)
+	if allRepos {
+		// Group workspaces by repository
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].Repo < entries[j].Repo
+		})
 	}
-
-	// Build unified list
-	entries := buildWorkspaceEntries(worktreeMap, sessionMap, mainWorkspaceNames)
 
 	if len(entries) == 0 {
 		fmt.Println(emptyStyle.Render("No workspaces found"))
@@ -158,7 +176,7 @@ func listWorkspaces() error {
 	// Render each workspace as a card
 	var activeCount, inactiveCount, orphanedCount, reviewCount int
 	for _, entry := range entries {
-		card := renderWorkspaceCard(entry)
+		card := renderWorkspaceCard(entry, allRepos)
 		fmt.Println(card)
 
 		switch entry.Status {
@@ -182,7 +200,8 @@ func listWorkspaces() error {
 }
 
 // renderWorkspaceCard creates a styled card for a workspace entry.
-func renderWorkspaceCard(e workspaceEntry) string {
+// showRepo adds the repository line for cross-repo listings.
+func renderWorkspaceCard(e workspaceEntry, showRepo bool) string {
 	// Status indicator and styles
 	var statusIcon, statusText string
 	var nameStyle lipgloss.Style
@@ -216,18 +235,36 @@ func renderWorkspaceCard(e workspaceEntry) string {
 	}
 
 	// Detail lines
-	lines := []string{
-		headerLine,
+	lines := []string{headerLine}
+	if showRepo {
+		lines = append(lines, fmt.Sprintf("    %s %s", labelStyle.Render("Repo:"), valueStyle.Render(e.Repo)))
+	}
+	lines = append(lines,
 		fmt.Sprintf("    %s %s", labelStyle.Render("Branch:"), valueStyle.Render(e.Branch)),
 		fmt.Sprintf("    %s %s", labelStyle.Render("Dir:"), valueStyle.Render(e.Dir)),
 		fmt.Sprintf("    %s %s", labelStyle.Render("Mode:"), valueStyle.Render(e.Mode)),
+		fmt.Sprintf("    %s %s", labelStyle.Render("Plan:"), renderPlanStatus(e.PlanStatus)),
+		fmt.Sprintf("    %s %s", labelStyle.Render("Progress:"), valueStyle.Render(e.Progress)),
 		fmt.Sprintf("    %s %s", labelStyle.Render("Status:"), statusText),
-	}
+	)
 
 	content := strings.Join(lines, "\n")
 	return baseCardStyle.Render(content)
 }
 
+// renderPlanStatus styles the plan approval status.
+func renderPlanStatus(status string) string {
+	switch workspace.ApprovalStatus(status) {
+	case workspace.ApprovalValid:
+		return statusActiveStyle.Render(status)
+	case workspace.ApprovalStale:
+		return reviewBadgeStyle.Render(status + " (changed since approval)")
+	case workspace.ApprovalNone:
+		return statusInactiveStyle.Render(status)
+	}
+	return valueStyle.Render(status)
+}
+
 // renderSummary creates the summary line.
 func renderSummary(total, active, inactive, orphaned, review int) string {
 	word := "workspace"
@@ -257,59 +294,87 @@ func renderSummary(total, active, inactive, orphaned, review int) string {
 	return summaryStyle.Render(summary)
 }
 
-// buildWorkspaceEntries combines worktrees and sessions into workspace entries.
-func buildWorkspaceEntries(worktrees map[string]stackit.WorktreeEntry, sessions map[string]bool, mainWorkspaces map[string]bool) []workspaceEntry {
-	seen := make(map[string]bool)
+// buildWorkspaceEntries combines known workspaces and sessions into workspace entries.
+func buildWorkspaceEntries(workspaces map[string]workspaceLocation, sessions map[string]bool) []workspaceEntry {
 	var entries []workspaceEntry
 
-	// Add all worktrees
-	for name, wt := range worktrees {
+	// Sessions are named after workspaces, so a name shared by several
+	// workspaces is resolved through the session's recorded worktree
+	counts := make(map[string]int)
+	for _, loc := range workspaces {
+		counts[loc.Name]++
+	}
+
+	// Add all workspaces found on disk
+	for _, loc := range workspaces {
+		name := loc.Name
 		status := "inactive"
-		if sessions[name] {
+		if sessions[name] && (counts[name] == 1 || sessionServes(name, loc.Path)) {
 			status = "active"
 		}
 
 		// Get mode and review state from workspace
 		mode := "-"
+		planStatus := "-"
+		progress := "-"
 		needsReview := false
-		ws := &workspace.Workspace{Name: name, WorktreePath: wt.Path}
+		ws := &workspace.Workspace{Name: name, WorktreePath: loc.Path}
 		if m, err := ws.GetMode(); err == nil {
 			mode = string(m)
 		}
+		if ps, err := ws.PlanApprovalStatus(); err == nil {
+			planStatus = string(ps)
+		}
+		if steps, err := plan.ParseFile(ws.PlanFile()); err == nil && len(steps) > 0 {
+			progress = plan.Summarize(steps).String()
+		}
 		if rs, err := ws.GetReviewState(); err == nil {
 			needsReview = rs.NeedsReview
 		}
 
 		entries = append(entries, workspaceEntry{
 			Name:        name,
-			Branch:      wt.Branch,
-			Dir:         filepath.Base(wt.Path),
+			Repo:        loc.Repo,
+			Branch:      loc.Branch,
+			Dir:         filepath.Base(loc.Path),
 			Status:      status,
 			Mode:        mode,
-			IsMain:      mainWorkspaces[name],
+			PlanStatus:  planStatus,
+			Progress:    progress,
+			IsMain:      loc.IsMain,
 			NeedsReview: needsReview,
 		})
-		seen[name] = true
 	}
 
 	// Add orphaned sessions (sessions without worktrees)
 	for name := range sessions {
-		if !seen[name] {
+		if counts[name] == 0 {
 			entries = append(entries, workspaceEntry{
-				Name:   name,
-				Branch: "-",
-				Dir:    "-",
-				Status: "orphaned",
-				Mode:   "-",
-				IsMain: mainWorkspaces[name],
+				Name:       name,
+				Repo:       "-",
+				Branch:     "-",
+				Dir:        "-",
+				Status:     "orphaned",
+				Mode:       "-",
+				PlanStatus: "-",
+				Progress:   "-",
 			})
 		}
 	}
 
-	// Sort by name
+	// Sort by name, then repository
 	sort.Slice(entries, func(i, j int) bool {
-		return entries[i].Name < entries[j].Name
+		if entries[i].Name != entries[j].Name {
+			return entries[i].Name < entries[j].Name
+		}
+		return entries[i].Repo < entries[j].Repo
 	})
 
 	return entries
 }
+
+// sessionServes reports whether the named workspace's session runs in path.
+func sessionServes(name, path string) bool {
+	sessionPath, err := getTmuxSessionEnv(sessionPrefix+name, "PLANQ_WORKTREE_PATH")
+	return err == nil && samePath(sessionPath, path)
+}
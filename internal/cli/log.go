@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"planq.dev/planq/internal/changelog"
+	"planq.dev/planq/internal/workspace"
+)
+
+var (
+	logAll  bool
+	logType string
+)
+
+var logCmd = &cobra.Command{
+	Use:   "log [name]",
+	Short: "Show the agent changelog of a workspace",
+	Long: `Show the changelog agents write with the planq_log MCP tool, oldest first.
+
+Entries are stored in .planq/agent/changelog.md of each workspace. With --all,
+the changelogs of every workspace in the repository are merged into one
+session timeline.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showLog(args)
+	},
+}
+
+func init() {
+	logCmd.Flags().BoolVarP(&logAll, "all", "a", false, "Merge the changelogs of every workspace in the repository")
+	logCmd.Flags().StringVarP(&logType, "type", "t", "", fmt.Sprintf("Only show entries of this type %v", changelog.Types))
+}
+
+// showLog prints the changelog of one workspace, or of every workspace with --all.
+func showLog(args []string) error {
+	var entryType changelog.Type
+	if logType != "" {
+		t, err := changelog.ParseType(logType)
+		if err != nil {
+			return err
+		}
+		entryType = t
+	}
+
+	if logAll {
+		if len(args) > 0 {
+			return fmt.Errorf("--all shows every workspace, drop the workspace name")
+		}
+		return showSessionLog(entryType)
+	}
+
+	ws, err := planWorkspace(args)
+	if err != nil {
+		return err
+	}
+	entries, err := changelog.Read(ws.ChangelogFile())
+	if err != nil {
+		return err
+	}
+	entries = changelog.Filter(entries, entryType)
+
+	if len(entries) == 0 {
+		fmt.Printf("No changelog entries for workspace %q\n", ws.Name)
+		return nil
+	}
+	for _, e := range entries {
+		printLogEntry(e, false)
+	}
+	return nil
+}
+
+// showSessionLog prints the merged changelog of the repository's workspaces.
+func showSessionLog(entryType changelog.Type) error {
+	repo := currentRepo()
+	var locs []workspaceLocation
+	for _, loc := range knownWorkspaces(nil) {
+		if repo == "" || samePath(loc.Repo, repo) {
+			locs = append(locs, loc)
+		}
+	}
+	sort.Slice(locs, func(i, j int) bool {
+		return locs[i].Name < locs[j].Name
+	})
+
+	logs := make(map[string][]changelog.Entry)
+	for _, loc := range locs {
+		ws := &workspace.Workspace{Name: loc.Name, WorktreePath: loc.Path}
+		entries, err := changelog.Read(ws.ChangelogFile())
+		if err != nil {
+			fmt.Printf("Warning: could not read changelog of %q: %v\n", loc.Name, err)
+			continue
+		}
+		logs[loc.Name] = append(logs[loc.Name], changelog.Filter(entries, entryType)...)
+	}
+
+	entries := changelog.Merge(logs)
+	if len(entries) == 0 {
+		fmt.Println("No changelog entries in this repository")
+		return nil
+	}
+	for _, e := range entries {
+		printLogEntry(e, true)
+	}
+	return nil
+}
+
+// printLogEntry prints an entry on one line, with further message lines indented below.
+func printLogEntry(e changelog.Entry, showAgent bool) {
+	prefix := fmt.Sprintf("%s  %-9s  %-11s", e.Time.Local().Format("2006-01-02 15:04:05"), e.Type, e.Status)
+	if showAgent {
+		prefix = fmt.Sprintf("%s  %-20s  %-9s  %-11s", e.Time.Local().Format("2006-01-02 15:04:05"), e.Agent, e.Type, e.Status)
+	}
+
+	lines := strings.Split(e.Message, "\n")
+	fmt.Printf("%s  %s\n", prefix, lines[0])
+	indent := strings.Repeat(" ", len(prefix)+2)
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			fmt.Println()
+			continue
+		}
+		fmt.Printf("%s%s\n", indent, line)
+	}
+}
@@ -3,14 +3,20 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/spf13/cobra"
 	"planq.dev/planq/internal/git"
 	"planq.dev/planq/internal/queue"
+	"planq.dev/planq/internal/workspace"
 )
 
 var mcpCmd = &cobra.Command{
@@ -28,6 +34,7 @@ func runMCPServer() error {
 		"planq",
 		"1.0.0",
 		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(true, true),
 	)
 
 	// Define the queue tool
@@ -37,29 +44,218 @@ func runMCPServer() error {
 			mcp.Required(),
 			mcp.Description("The text to queue (plan, bug, idea, etc.)"),
 		),
+		mcp.WithString("title",
+			mcp.Description("Short title (default: first line of the text)"),
+		),
+		mcp.WithNumber("priority",
+			mcp.Description("Priority from 1 (highest) to 5 (lowest), default 3"),
+		),
+		mcp.WithString("tags",
+			mcp.Description("Comma-separated tags"),
+		),
 	)
 	s.AddTool(queueTool, queueHandler)
 
 	// Define the list tool
 	listTool := mcp.NewTool("planq_list",
-		mcp.WithDescription("List all queued items. Returns items sorted oldest first."),
+		mcp.WithDescription("List queued items as structured data, most urgent first. Only open items are returned unless status is given."),
+		mcp.WithString("status",
+			mcp.Description("Only return items with this status: open, claimed, done, archived or all (default: open)"),
+		),
+		mcp.WithString("tag",
+			mcp.Description("Only return items with this tag"),
+		),
 	)
 	s.AddTool(listTool, listHandler)
 
-	// Start the stdio server
-	if err := server.ServeStdio(s); err != nil {
+	// Define the queue claim tools
+	claimTool := mcp.NewTool("planq_queue_claim",
+		mcp.WithDescription("Claim a queued item before working on it so that other agents skip it. The claim is a lease: claim the item again to renew it, or it is put back in the queue when it expires."),
+		mcp.WithNumber("id",
+			mcp.Required(),
+			mcp.Description("The item id as returned by planq_list"),
+		),
+		mcp.WithNumber("lease_minutes",
+			mcp.Description(fmt.Sprintf("How long the claim lasts (default: %d)", int(queue.DefaultLeaseDuration.Minutes()))),
+		),
+		mcp.WithString("workspace",
+			mcp.Description("The claiming workspace (default: the current workspace)"),
+		),
+	)
+	s.AddTool(claimTool, claimHandler)
+
+	releaseTool := mcp.NewTool("planq_queue_release",
+		mcp.WithDescription("Give up a claim and put the item back in the queue for another agent."),
+		mcp.WithNumber("id",
+			mcp.Required(),
+			mcp.Description("The item id"),
+		),
+		mcp.WithString("workspace",
+			mcp.Description("The workspace holding the claim (default: the current workspace)"),
+		),
+	)
+	s.AddTool(releaseTool, releaseHandler)
+
+	completeTool := mcp.NewTool("planq_queue_complete",
+		mcp.WithDescription("Mark a claimed item as done."),
+		mcp.WithNumber("id",
+			mcp.Required(),
+			mcp.Description("The item id"),
+		),
+		mcp.WithString("workspace",
+			mcp.Description("The workspace holding the claim (default: the current workspace)"),
+		),
+	)
+	s.AddTool(completeTool, completeHandler)
+
+	// Define the plan status tool
+	planStatusTool := mcp.NewTool("planq_plan_status",
+		mcp.WithDescription("Show the checklist steps of the workspace plan and how many are done."),
+	)
+	s.AddTool(planStatusTool, planStatusHandler)
+
+	// Define the plan check tool
+	planCheckTool := mcp.NewTool("planq_plan_check",
+		mcp.WithDescription("Tick off a checklist step in the workspace plan without rewriting the file."),
+		mcp.WithNumber("step",
+			mcp.Required(),
+			mcp.Description("The step number as shown by planq_plan_status"),
+		),
+		mcp.WithBoolean("done",
+			mcp.Description("Whether the step is done (default: true)"),
+		),
+	)
+	s.AddTool(planCheckTool, planCheckHandler)
+
+	// Define the changelog tool
+	logTool := mcp.NewTool("planq_log",
+		mcp.WithDescription("Add an entry to the workspace changelog, the record of your work that other agents and the user read. Log when you start, settle on a plan, finish a piece of work, make a decision or hand off."),
+		mcp.WithString("message",
+			mcp.Required(),
+			mcp.Description("What happened (markdown)"),
+		),
+		mcp.WithString("type",
+			mcp.Description("Entry type (default: implement)"),
+			mcp.Enum(changelogTypeNames()...),
+		),
+		mcp.WithString("status",
+			mcp.Description("Progress of the work"),
+			mcp.Enum(changelogStatusNames()...),
+		),
+	)
+	s.AddTool(logTool, logHandler)
+
+	// Define the mode tools
+	modeGetTool := mcp.NewTool("planq_mode_get",
+		mcp.WithDescription("Show the workspace mode, the modes it can switch to and whether the plan is approved."),
+	)
+	s.AddTool(modeGetTool, modeGetHandler)
+
+	modeSetTool := mcp.NewTool("planq_mode_set",
+		mcp.WithDescription("Switch the workspace mode and reconfigure its tmux layout. Entering execute mode requires a plan the user approved with 'planq plan approve'."),
+		mcp.WithString("mode",
+			mcp.Required(),
+			mcp.Description(fmt.Sprintf("The mode to switch to: %s, or toggle", strings.Join(workspace.ModeNames(), ", "))),
+		),
+	)
+	s.AddTool(modeSetTool, modeSetHandler)
+
+	// Define the scratch pad tools
+	scratchReadTool := mcp.NewTool("planq_scratch_read",
+		mcp.WithDescription("Read your scratch pad: working notes for this workspace that survive context resets. Check it when resuming work."),
+	)
+	s.AddTool(scratchReadTool, scratchReadHandler)
+
+	scratchAppendTool := mcp.NewTool("planq_scratch_append",
+		mcp.WithDescription(fmt.Sprintf("Add a timestamped note to your scratch pad, e.g. findings, decisions in progress or next steps. Notes are limited to %d bytes and the pad to %d bytes.", workspace.MaxScratchAppend, workspace.MaxScratchSize)),
+		mcp.WithString("text",
+			mcp.Required(),
+			mcp.Description("The note (markdown)"),
+		),
+		mcp.WithString("section",
+			mcp.Description("Optional section header for the note"),
+		),
+	)
+	s.AddTool(scratchAppendTool, scratchAppendHandler)
+
+	scratchReplaceTool := mcp.NewTool("planq_scratch_replace",
+		mcp.WithDescription("Replace your whole scratch pad, e.g. with a summary when it is getting full."),
+		mcp.WithString("content",
+			mcp.Required(),
+			mcp.Description("The new scratch pad content (markdown)"),
+		),
+	)
+	s.AddTool(scratchReplaceTool, scratchReplaceHandler)
+
+	// Define the decision and learning tools
+	decideTool := mcp.NewTool("planq_decide",
+		mcp.WithDescription("Record a decision between alternatives and why you made it. Decisions are kept in the repository after the workspace is removed; browse them with 'planq decisions'."),
+		mcp.WithString("decision",
+			mcp.Required(),
+			mcp.Description("What you decided, in one line"),
+		),
+		mcp.WithArray("alternatives",
+			mcp.Description("The options you considered and rejected"),
+			mcp.WithStringItems(),
+		),
+		mcp.WithString("rationale",
+			mcp.Required(),
+			mcp.Description("Why this option won (markdown)"),
+		),
+	)
+	s.AddTool(decideTool, decideHandler)
+
+	learnTool := mcp.NewTool("planq_learn",
+		mcp.WithDescription("Record something you learned about the codebase or tooling that later work should know. Learnings are kept in the repository after the workspace is removed; browse them with 'planq learnings'."),
+		mcp.WithString("insight",
+			mcp.Required(),
+			mcp.Description("What you learned, in one line"),
+		),
+		mcp.WithString("context",
+			mcp.Description("How you found out and where it applies (markdown)"),
+		),
+	)
+	s.AddTool(learnTool, learnHandler)
+
+	// Publish the workspace files and the queue as resources
+	watcher := addResources(s)
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	go watcher.run(ctx)
+
+	// Start the stdio server, answering resource subscriptions on its behalf
+	stdout := &syncWriter{w: os.Stdout}
+	if err := server.NewStdioServer(s).Listen(ctx, watcher.filter(os.Stdin, stdout), stdout); err != nil {
 		return fmt.Errorf("server error: %w", err)
 	}
 
 	return nil
 }
 
-// getProjectRoot returns the project root from env or git.
+// syncWriter serialises writes, so that the subscription responses and the
+// server's own messages do not interleave.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// getProjectRoot returns the project root from env or git. Inside a linked
+// worktree this is the main worktree, so that all workspaces share one queue.
 func getProjectRoot() (string, error) {
 	if root := os.Getenv("PLANQ_PROJECT_ROOT"); root != "" {
 		return root, nil
 	}
-	return git.GetRepoRoot()
+	root, err := git.GetRepoRoot()
+	if err != nil {
+		return "", err
+	}
+	return git.GetMainRepoRoot(root)
 }
 
 func queueHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -73,12 +269,30 @@ func queueHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallTo
 		return mcp.NewToolResultError(fmt.Sprintf("failed to find project root: %v (set PLANQ_PROJECT_ROOT to override)", err)), nil
 	}
 
-	filePath, err := queue.Add(projectRoot, text)
+	var tags []string
+	for _, tag := range strings.Split(request.GetString("tags", ""), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	item, err := queue.Add(projectRoot, text, queue.AddOptions{
+		Title:    request.GetString("title", ""),
+		Priority: request.GetInt("priority", queue.DefaultPriority),
+		Tags:     tags,
+		Source:   os.Getenv("PLANQ_WORKSPACE"),
+	})
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to queue: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Queued to %s", filePath)), nil
+	return mcp.NewToolResultText(fmt.Sprintf("Queued #%d to %s", item.ID, queue.Path(projectRoot, item))), nil
+}
+
+// queueListResult is the structured result of planq_list.
+type queueListResult struct {
+	Count int          `json:"count"`
+	Items []queue.Item `json:"items"`
 }
 
 func listHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -87,20 +301,90 @@ func listHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToo
 		return mcp.NewToolResultError(fmt.Sprintf("failed to find project root: %v (set PLANQ_PROJECT_ROOT to override)", err)), nil
 	}
 
-	items, err := queue.List(projectRoot)
+	filter := queue.Filter{}
+	if status := request.GetString("status", string(queue.StatusOpen)); status != "all" {
+		filter.Status, err = queue.ParseStatus(status)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	if tag := request.GetString("tag", ""); tag != "" {
+		filter.Tags = []string{tag}
+	}
+
+	items, err := queue.List(projectRoot, filter)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to list queue: %v", err)), nil
 	}
+	if items == nil {
+		items = []queue.Item{}
+	}
+
+	return mcp.NewToolResultStructuredOnly(queueListResult{Count: len(items), Items: items}), nil
+}
+
+// claimRequest returns the project root, item ID and workspace of a claim tool call.
+func claimRequest(request mcp.CallToolRequest) (string, int, string, error) {
+	id, err := request.RequireInt("id")
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	workspace := request.GetString("workspace", os.Getenv("PLANQ_WORKSPACE"))
+	if workspace == "" {
+		return "", 0, "", fmt.Errorf("workspace required: pass workspace or run inside a planq workspace")
+	}
+
+	projectRoot, err := getProjectRoot()
+	if err != nil {
+		return "", 0, "", fmt.Errorf("failed to find project root: %v (set PLANQ_PROJECT_ROOT to override)", err)
+	}
+	return projectRoot, id, workspace, nil
+}
+
+func claimHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectRoot, id, workspace, err := claimRequest(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	if len(items) == 0 {
-		return mcp.NewToolResultText("No items in queue"), nil
+	lease := queue.DefaultLeaseDuration
+	if minutes := request.GetInt("lease_minutes", 0); minutes > 0 {
+		lease = time.Duration(minutes) * time.Minute
 	}
 
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("%d item(s) in queue:\n\n", len(items)))
-	for _, item := range items {
-		sb.WriteString(fmt.Sprintf("## %s\n%s\n\n", item.Filename, item.Content))
+	item, err := queue.Claim(projectRoot, id, workspace, lease)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultStructuredOnly(item), nil
+}
+
+func releaseHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectRoot, id, workspace, err := claimRequest(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	item, err := queue.Release(projectRoot, id, workspace)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Released #%d: %s", item.ID, item.Title)), nil
+}
+
+func completeHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectRoot, id, workspace, err := claimRequest(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	item, err := queue.Complete(projectRoot, id, workspace)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	return mcp.NewToolResultText(sb.String()), nil
+	return mcp.NewToolResultText(fmt.Sprintf("Done: #%d %s", item.ID, item.Title)), nil
 }
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"planq.dev/planq/internal/knowledge"
+	"planq.dev/planq/internal/workspace"
+)
+
+// knowledgeRepoDir returns the repository-level directory for records of
+// kind, which outlives the workspaces that wrote them.
+func knowledgeRepoDir(kind knowledge.Kind) (string, error) {
+	projectRoot, err := getProjectRoot()
+	if err != nil {
+		return "", fmt.Errorf("failed to find project root: %v (set PLANQ_PROJECT_ROOT to override)", err)
+	}
+	return knowledge.Dir(filepath.Join(projectRoot, workspace.PlanqDirName), kind), nil
+}
+
+// saveKnowledge writes r to the workspace's agent directory and to the
+// repository store.
+func saveKnowledge(r knowledge.Record) (*knowledge.Record, error) {
+	ws, _, err := mcpWorkspace()
+	if err != nil {
+		return nil, err
+	}
+	repoDir, err := knowledgeRepoDir(r.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Workspace = ws.Name
+	return knowledge.Save(r, knowledge.Dir(ws.AgentDir(), r.Kind), repoDir)
+}
+
+func decideHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	decision, err := request.RequireString("decision")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	rationale, err := request.RequireString("rationale")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	record, err := saveKnowledge(knowledge.Record{
+		Kind:         knowledge.KindDecision,
+		Summary:      decision,
+		Alternatives: request.GetStringSlice("alternatives", nil),
+		Detail:       rationale,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Recorded decision %q", record.Summary)), nil
+}
+
+func learnHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	insight, err := request.RequireString("insight")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	record, err := saveKnowledge(knowledge.Record{
+		Kind:    knowledge.KindLearning,
+		Summary: insight,
+		Detail:  request.GetString("context", ""),
+	})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Recorded learning %q", record.Summary)), nil
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"planq.dev/planq/internal/changelog"
+)
+
+// changelogTypeNames lists the entry types for the planq_log schema.
+func changelogTypeNames() []string {
+	names := make([]string, len(changelog.Types))
+	for i, t := range changelog.Types {
+		names[i] = string(t)
+	}
+	return names
+}
+
+// changelogStatusNames lists the statuses for the planq_log schema.
+func changelogStatusNames() []string {
+	names := make([]string, len(changelog.Statuses))
+	for i, s := range changelog.Statuses {
+		names[i] = string(s)
+	}
+	return names
+}
+
+func logHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	message, err := request.RequireString("message")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	entryType, err := changelog.ParseType(request.GetString("type", string(changelog.TypeImplement)))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var status changelog.Status
+	if s := request.GetString("status", ""); s != "" {
+		status, err = changelog.ParseStatus(s)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	ws, _, err := mcpWorkspace()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	entry, err := changelog.Append(ws.ChangelogFile(), changelog.Entry{Type: entryType, Status: status, Message: message})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Logged %s entry at %s", entry.Type, entry.Time.Format("15:04:05 MST"))), nil
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"planq.dev/planq/internal/workspace"
+)
+
+// modeResult is the structured result of the mode tools.
+type modeResult struct {
+	Workspace    string                   `json:"workspace"`
+	Mode         workspace.Mode           `json:"mode"`
+	Previous     workspace.Mode           `json:"previous,omitempty"`
+	Description  string                   `json:"description,omitempty"`
+	Transitions  []workspace.Mode         `json:"transitions"`
+	PlanApproval workspace.ApprovalStatus `json:"plan_approval"`
+	// Layout reports how the tmux session was reconfigured.
+	Layout string `json:"layout,omitempty"`
+}
+
+// workspaceModeResult describes the current mode of ws.
+func workspaceModeResult(ws *workspace.Workspace) (*modeResult, error) {
+	mode, err := ws.GetMode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mode: %w", err)
+	}
+	approval, err := ws.PlanApprovalStatus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check plan approval: %w", err)
+	}
+
+	result := &modeResult{
+		Workspace:    ws.Name,
+		Mode:         mode,
+		Transitions:  []workspace.Mode{},
+		PlanApproval: approval,
+	}
+	if spec, err := workspace.LookupMode(mode); err == nil {
+		result.Description = spec.Description
+		result.Transitions = append(result.Transitions, spec.Transitions...)
+	}
+	return result, nil
+}
+
+func modeGetHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ws, _, err := mcpWorkspace()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result, err := workspaceModeResult(ws)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultStructuredOnly(result), nil
+}
+
+func modeSetHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	target, err := request.RequireString("mode")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	ws, meta, err := mcpWorkspace()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	previous, err := ws.GetMode()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get current mode: %v", err)), nil
+	}
+
+	// The agent never skips the approval check; only the user may force it
+	opts := workspace.SwitchOptions{Trigger: workspace.TriggerMCP}
+	if target == "toggle" {
+		if _, err := ws.ToggleMode(opts); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to toggle mode: %v", err)), nil
+		}
+	} else {
+		mode := workspace.Mode(target)
+		if _, err := workspace.LookupMode(mode); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid mode %q: use one of %s, or 'toggle'", target, strings.Join(workspace.ModeNames(), ", "))), nil
+		}
+		if err := ws.SetMode(mode, opts); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to set mode: %v", err)), nil
+		}
+	}
+
+	result, err := workspaceModeResult(ws)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	result.Previous = previous
+
+	// Reconfigure like 'planq mode' does, but keep stdout free for the protocol
+	var out bytes.Buffer
+	if err := reconfigureSession(&out, ws, meta, result.Mode); err != nil {
+		fmt.Fprintf(&out, "Warning: %v\n", err)
+	}
+	result.Layout = strings.TrimSpace(out.String())
+
+	return mcp.NewToolResultStructuredOnly(result), nil
+}
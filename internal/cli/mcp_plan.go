@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"planq.dev/planq/internal/plan"
+	"planq.dev/planq/internal/workspace"
+)
+
+// mcpWorkspace returns the workspace the MCP server was started in and its
+// metadata. The worktree is found by walking up from PLANQ_WORKTREE_PATH, or
+// the working directory, to .planq/workspace.json, whose name must match
+// PLANQ_WORKSPACE.
+func mcpWorkspace() (*workspace.Workspace, *workspace.Metadata, error) {
+	name := os.Getenv("PLANQ_WORKSPACE")
+	if name == "" {
+		return nil, nil, fmt.Errorf("not running in a planq workspace (PLANQ_WORKSPACE is not set)")
+	}
+
+	workdir := os.Getenv("PLANQ_WORKTREE_PATH")
+	if workdir == "" {
+		var err error
+		workdir, err = os.Getwd()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get working directory: %w", err)
+		}
+	}
+
+	meta, root, err := workspace.FindMetadata(workdir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if meta == nil {
+		return nil, nil, fmt.Errorf("no workspace.json found in %s or its parents (run 'planq doctor --fix')", workdir)
+	}
+	if meta.Name != name {
+		return nil, nil, fmt.Errorf("PLANQ_WORKSPACE is %q but %s belongs to workspace %q", name, root, meta.Name)
+	}
+
+	return &workspace.Workspace{Name: meta.Name, WorktreePath: root, AgentName: meta.Agent}, meta, nil
+}
+
+func planStatusHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ws, _, err := mcpWorkspace()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	steps, err := plan.ParseFile(ws.PlanFile())
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read plan: %v", err)), nil
+	}
+
+	if len(steps) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("The plan at %s has no checklist steps", ws.PlanFile())), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Plan progress: %s\n\n", plan.Summarize(steps)))
+	for _, step := range steps {
+		mark := " "
+		if step.Done {
+			mark = "x"
+		}
+		sb.WriteString(fmt.Sprintf("%d. [%s] %s\n", step.Number, mark, step.Text))
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+func planCheckHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	number, err := request.RequireInt("step")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	done := request.GetBool("done", true)
+
+	ws, _, err := mcpWorkspace()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	step, err := plan.CheckStep(ws.PlanFile(), number, done)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to update plan: %v", err)), nil
+	}
+
+	steps, err := plan.ParseFile(ws.PlanFile())
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read plan: %v", err)), nil
+	}
+
+	state := "done"
+	if !step.Done {
+		state = "not done"
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Marked step %d (%s) as %s. Plan progress: %s", step.Number, step.Text, state, plan.Summarize(steps))), nil
+}
@@ -0,0 +1,346 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"planq.dev/planq/internal/queue"
+)
+
+// Resource URIs published by the MCP server.
+const (
+	planResourceURI      = "planq://workspace/plan"
+	scratchResourceURI   = "planq://workspace/scratch"
+	metadataResourceURI  = "planq://workspace/metadata"
+	queueResourceURI     = "planq://queue"
+	queueItemResourceURI = "planq://queue/{id}"
+)
+
+// Subscription methods, which mcp-go does not handle itself.
+const (
+	methodResourcesSubscribe   = "resources/subscribe"
+	methodResourcesUnsubscribe = "resources/unsubscribe"
+)
+
+// resourcePollInterval is how often the files behind the resources are
+// checked for changes.
+const resourcePollInterval = time.Second
+
+// addResources publishes the workspace files and the queue as resources and
+// returns the watcher that reports changes to them. Workspace resources are
+// only published inside a workspace, and queue resources only inside a
+// repository.
+func addResources(s *server.MCPServer) *resourceWatcher {
+	w := &resourceWatcher{server: s, files: make(map[string]string), subscribed: make(map[string]bool)}
+
+	if ws, _, err := mcpWorkspace(); err == nil {
+		addFileResource(s, w, planResourceURI, "Plan", "The workspace plan file", "text/markdown", ws.PlanFile())
+		addFileResource(s, w, scratchResourceURI, "Scratch pad", "The agent's working notes for the workspace", "text/markdown", ws.ScratchFile())
+		addFileResource(s, w, metadataResourceURI, "Workspace metadata", "Name, repository, branch and agent of the workspace", "application/json", ws.MetadataFile())
+	}
+
+	if projectRoot, err := getProjectRoot(); err == nil {
+		w.projectRoot = projectRoot
+		s.AddResource(
+			mcp.NewResource(queueResourceURI, "Queue",
+				mcp.WithResourceDescription("Open and claimed queue items, most urgent first"),
+				mcp.WithMIMEType("application/json"),
+			),
+			queueResourceHandler(projectRoot),
+		)
+		s.AddResourceTemplate(
+			mcp.NewResourceTemplate(queueItemResourceURI, "Queue item",
+				mcp.WithTemplateDescription("A queue item by ID, with its body and lease"),
+				mcp.WithTemplateMIMEType("application/json"),
+			),
+			queueItemResourceHandler(projectRoot),
+		)
+	}
+
+	return w
+}
+
+// addFileResource publishes the file at path under uri and watches it.
+func addFileResource(s *server.MCPServer, w *resourceWatcher, uri, name, description, mimeType, path string) {
+	s.AddResource(
+		mcp.NewResource(uri, name,
+			mcp.WithResourceDescription(description),
+			mcp.WithMIMEType(mimeType),
+		),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			// A file that does not exist yet reads as empty
+			data, err := os.ReadFile(path)
+			if err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			return []mcp.ResourceContents{
+				mcp.TextResourceContents{URI: uri, MIMEType: mimeType, Text: string(data)},
+			}, nil
+		},
+	)
+	w.files[uri] = path
+}
+
+func queueResourceHandler(projectRoot string) server.ResourceHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		items, err := queue.List(projectRoot, queue.Filter{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list queue: %w", err)
+		}
+
+		active := []queue.Item{}
+		for _, item := range items {
+			if item.Status == queue.StatusOpen || item.Status == queue.StatusClaimed {
+				active = append(active, item)
+			}
+		}
+		return jsonResource(queueResourceURI, queueListResult{Count: len(active), Items: active})
+	}
+}
+
+func queueItemResourceHandler(projectRoot string) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		id, err := queue.ParseID(strings.TrimPrefix(request.Params.URI, queueResourceURI+"/"))
+		if err != nil {
+			return nil, err
+		}
+		item, err := queue.Get(projectRoot, id)
+		if err != nil {
+			return nil, err
+		}
+		return jsonResource(request.Params.URI, item)
+	}
+}
+
+// jsonResource encodes v as the contents of the resource at uri.
+func jsonResource(uri string, v any) ([]mcp.ResourceContents, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", uri, err)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: uri, MIMEType: "application/json", Text: string(data)},
+	}, nil
+}
+
+// resourceWatcher polls the files behind the resources and notifies the
+// client of changes to the resources it subscribed to.
+type resourceWatcher struct {
+	server *server.MCPServer
+	// files maps resource URIs to the files they publish.
+	files map[string]string
+	// projectRoot is the repository whose queue is published, if any.
+	projectRoot string
+
+	mu         sync.Mutex
+	subscribed map[string]bool
+
+	stamps     map[string]string
+	queueStamp string
+	items      map[int]string
+}
+
+// run polls until ctx is cancelled.
+func (w *resourceWatcher) run(ctx context.Context) {
+	// The first poll records the current state without notifying
+	w.poll()
+
+	ticker := time.NewTicker(resourcePollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, listChanged := w.poll()
+			for _, uri := range changed {
+				if w.isSubscribed(uri) {
+					w.server.SendNotificationToAllClients(mcp.MethodNotificationResourceUpdated, map[string]any{"uri": uri})
+				}
+			}
+			if listChanged {
+				w.server.SendNotificationToAllClients(mcp.MethodNotificationResourcesListChanged, nil)
+			}
+		}
+	}
+}
+
+// subscribe records whether the client wants to be notified of changes to uri.
+func (w *resourceWatcher) subscribe(uri string, on bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if on {
+		w.subscribed[uri] = true
+	} else {
+		delete(w.subscribed, uri)
+	}
+}
+
+// isSubscribed reports whether the client subscribed to uri.
+func (w *resourceWatcher) isSubscribed(uri string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.subscribed[uri]
+}
+
+// filter answers the subscription requests read from in and passes every
+// other message on through the returned reader. Responses are written to out.
+func (w *resourceWatcher) filter(in io.Reader, out io.Writer) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 && !w.handleSubscription(line, out) {
+				if _, err := pw.Write(line); err != nil {
+					return
+				}
+			}
+			if err != nil {
+				if err == io.EOF {
+					err = nil
+				}
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	return pr
+}
+
+// handleSubscription answers line if it is a subscription request and
+// reports whether it was one.
+func (w *resourceWatcher) handleSubscription(line []byte, out io.Writer) bool {
+	var request struct {
+		ID     *mcp.RequestId      `json:"id"`
+		Method string              `json:"method"`
+		Params mcp.SubscribeParams `json:"params"`
+	}
+	if err := json.Unmarshal(line, &request); err != nil || request.ID == nil {
+		return false
+	}
+
+	var response any
+	switch {
+	case request.Method != methodResourcesSubscribe && request.Method != methodResourcesUnsubscribe:
+		return false
+	case request.Params.URI == "":
+		response = mcp.NewJSONRPCError(*request.ID, mcp.INVALID_PARAMS, "uri is required", nil)
+	default:
+		w.subscribe(request.Params.URI, request.Method == methodResourcesSubscribe)
+		response = mcp.NewJSONRPCResultResponse(*request.ID, mcp.EmptyResult{})
+	}
+
+	data, err := json.Marshal(response)
+	if err == nil {
+		_, _ = out.Write(append(data, '\n'))
+	}
+	return true
+}
+
+// poll returns the URIs of the resources that changed since the last poll,
+// and whether queue items were added or removed.
+func (w *resourceWatcher) poll() (changed []string, listChanged bool) {
+	stamps := make(map[string]string, len(w.files))
+	for uri, path := range w.files {
+		stamps[uri] = fileStamp(path)
+		if w.stamps != nil && stamps[uri] != w.stamps[uri] {
+			changed = append(changed, uri)
+		}
+	}
+	w.stamps = stamps
+
+	if w.projectRoot != "" {
+		queueChanged, itemsChanged := w.pollQueue()
+		changed = append(changed, queueChanged...)
+		listChanged = itemsChanged
+	}
+	return changed, listChanged
+}
+
+// pollQueue returns the queue resources that changed, and whether items were
+// added or removed. Items are only reloaded when a file in the queue
+// directory changed.
+func (w *resourceWatcher) pollQueue() ([]string, bool) {
+	stamp := dirStamp(queue.Dir(w.projectRoot))
+	if w.items != nil && stamp == w.queueStamp {
+		return nil, false
+	}
+	w.queueStamp = stamp
+
+	items, err := queue.List(w.projectRoot, queue.Filter{})
+	if err != nil {
+		// Try again on the next change
+		w.queueStamp = ""
+		return nil, false
+	}
+
+	current := make(map[int]string, len(items))
+	for _, item := range items {
+		data, _ := json.Marshal(item)
+		current[item.ID] = string(data)
+	}
+
+	var changed []string
+	listChanged := false
+	if w.items != nil {
+		for id, data := range current {
+			old, existed := w.items[id]
+			if !existed {
+				listChanged = true
+			}
+			if old != data {
+				changed = append(changed, queueItemURI(id))
+			}
+		}
+		for id := range w.items {
+			if _, ok := current[id]; !ok {
+				changed = append(changed, queueItemURI(id))
+				listChanged = true
+			}
+		}
+		if len(changed) > 0 {
+			changed = append(changed, queueResourceURI)
+		}
+	}
+	w.items = current
+	return changed, listChanged
+}
+
+// queueItemURI returns the resource URI of a queue item.
+func queueItemURI(id int) string {
+	return queueResourceURI + "/" + strconv.Itoa(id)
+}
+
+// fileStamp identifies the current version of a file, or "" if it is missing.
+func fileStamp(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d", info.ModTime().UnixNano(), info.Size())
+}
+
+// dirStamp identifies the current version of the files in a directory.
+func dirStamp(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "%s=%s;", entry.Name(), fileStamp(filepath.Join(dir, entry.Name())))
+	}
+	return b.String()
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"planq.dev/planq/internal/queue"
+)
+
+func TestResourceWatcherPoll(t *testing.T) {
+	root := t.TempDir()
+	plan := filepath.Join(root, "plan.md")
+	w := &resourceWatcher{
+		files:       map[string]string{planResourceURI: plan},
+		projectRoot: root,
+		subscribed:  make(map[string]bool),
+	}
+
+	first, err := queue.Add(root, "First item", queue.AddOptions{})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	// The first poll only records the state
+	if changed, listChanged := w.poll(); len(changed) != 0 || listChanged {
+		t.Fatalf("first poll = %v, %v; want nothing", changed, listChanged)
+	}
+
+	// A missing file that appears is a change
+	if err := os.WriteFile(plan, []byte("- [ ] step\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if changed, listChanged := w.poll(); !reflect.DeepEqual(changed, []string{planResourceURI}) || listChanged {
+		t.Errorf("poll after plan write = %v, %v", changed, listChanged)
+	}
+	if changed, _ := w.poll(); len(changed) != 0 {
+		t.Errorf("poll without changes = %v", changed)
+	}
+
+	// Updating an item changes it and the queue, but not the list
+	if _, err := queue.SetPriority(root, first.ID, 1); err != nil {
+		t.Fatal(err)
+	}
+	changed, listChanged := w.poll()
+	sort.Strings(changed)
+	if want := []string{queueResourceURI, queueItemURI(first.ID)}; !reflect.DeepEqual(changed, want) || listChanged {
+		t.Errorf("poll after update = %v, %v; want %v, false", changed, listChanged, want)
+	}
+
+	// Adding an item changes the list
+	second, err := queue.Add(root, "Second item", queue.AddOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, listChanged = w.poll()
+	sort.Strings(changed)
+	if want := []string{queueResourceURI, queueItemURI(second.ID)}; !reflect.DeepEqual(changed, want) || !listChanged {
+		t.Errorf("poll after add = %v, %v; want %v, true", changed, listChanged, want)
+	}
+}
+
+func TestResourceWatcherFilter(t *testing.T) {
+	w := &resourceWatcher{subscribed: make(map[string]bool)}
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"planq://queue"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"planq://queue"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/subscribe","params":{"uri":"planq://workspace/plan"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"resources/unsubscribe","params":{"uri":"planq://workspace/plan"}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"resources/subscribe","params":{}}`,
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	passed, err := io.ReadAll(w.filter(strings.NewReader(input), &out))
+	if err != nil {
+		t.Fatalf("reading filtered input: %v", err)
+	}
+
+	if want := `{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"planq://queue"}}` + "\n"; string(passed) != want {
+		t.Errorf("passed on %q, want %q", passed, want)
+	}
+	if !w.isSubscribed(queueResourceURI) || w.isSubscribed(planResourceURI) {
+		t.Errorf("subscribed = %v", w.subscribed)
+	}
+
+	responses := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(responses) != 4 {
+		t.Fatalf("responses = %q, want 4", responses)
+	}
+	if responses[0] != `{"jsonrpc":"2.0","id":1,"result":{}}` {
+		t.Errorf("subscribe response = %s", responses[0])
+	}
+	if !strings.Contains(responses[3], `"id":5,"error"`) {
+		t.Errorf("response to subscribe without uri = %s", responses[3])
+	}
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"planq.dev/planq/internal/workspace"
+)
+
+// scratchUsage reports how much of the scratch pad is used.
+func scratchUsage(ws *workspace.Workspace) string {
+	content, err := ws.ReadScratch()
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf(" (%d of %d bytes used)", len(content), workspace.MaxScratchSize)
+}
+
+func scratchReadHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ws, _, err := mcpWorkspace()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	content, err := ws.ReadScratch()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if strings.TrimSpace(content) == "" {
+		return mcp.NewToolResultText("The scratch pad is empty."), nil
+	}
+	return mcp.NewToolResultText(content), nil
+}
+
+func scratchAppendHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	text, err := request.RequireString("text")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	ws, _, err := mcpWorkspace()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	section, err := ws.AppendScratch(text, request.GetString("section", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	header, _, _ := strings.Cut(section, "\n")
+	return mcp.NewToolResultText(fmt.Sprintf("Added %q to the scratch pad%s", strings.TrimPrefix(header, "## "), scratchUsage(ws))), nil
+}
+
+func scratchReplaceHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	content, err := request.RequireString("content")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	ws, _, err := mcpWorkspace()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if err := ws.ReplaceScratch(content); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText("Replaced the scratch pad" + scratchUsage(ws)), nil
+}
@@ -2,26 +2,41 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"planq.dev/planq/internal/config"
 	"planq.dev/planq/internal/tmux"
 	"planq.dev/planq/internal/workspace"
 )
 
 var modeWorkspace string
 var modeWorktree string
+var modeTrigger string
+var modeForce bool
 
 var modeCmd = &cobra.Command{
-	Use:   "mode [plan|execute|toggle]",
+	Use:   "mode [plan|execute|review|test|toggle]",
 	Short: "Switch or show workspace mode",
-	Long: `Switch between plan and execute modes, or show the current mode.
+	Long: `Switch between workspace modes, or show the current mode.
 
 Without arguments, shows the current mode.
-With 'plan' or 'execute', switches to that mode.
-With 'toggle', switches to the opposite mode.`,
+With a mode name, switches to that mode if the current mode allows it.
+With 'toggle', switches to the current mode's default next mode
+(plan and execute toggle between each other).
+
+Entering execute mode requires a plan approved with 'planq plan approve'
+that has not changed since. Use --force to skip the check; like approving,
+it must be done by a person in a terminal.
+
+Modes:
+  plan      Agent writes the plan file, no code changes
+  execute   Agent implements the plan
+  review    Agent reviews the changes against the plan
+  test      Agent writes and runs tests for the changes`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
@@ -31,12 +46,34 @@ With 'toggle', switches to the opposite mode.`,
 	},
 }
 
+var modeHistoryCmd = &cobra.Command{
+	Use:   "history [name]",
+	Short: "Show mode transition history",
+	Long: `Show every mode transition recorded for a workspace, oldest first.
+
+Each entry shows when the switch happened, the modes involved, what
+triggered it (cli, keybinding, skill or mcp) and the plan hash at that moment.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			modeWorkspace = args[0]
+		}
+		return showModeHistory()
+	},
+}
+
 func init() {
-	modeCmd.Flags().StringVarP(&modeWorkspace, "workspace", "w", "", "Workspace name (default: detect from environment)")
-	modeCmd.Flags().StringVar(&modeWorktree, "worktree", "", "Worktree path (default: detect from environment or cwd)")
+	modeCmd.PersistentFlags().StringVarP(&modeWorkspace, "workspace", "w", "", "Workspace name (default: detect from environment)")
+	modeCmd.PersistentFlags().StringVar(&modeWorktree, "worktree", "", "Worktree path (default: look the workspace up in the registry)")
+	modeCmd.Flags().StringVar(&modeTrigger, "trigger", string(workspace.TriggerCLI), "What triggered the switch (cli, keybinding, skill, mcp)")
+	_ = modeCmd.Flags().MarkHidden("trigger")
+	modeCmd.Flags().BoolVar(&modeForce, "force", false, "Enter execute mode without an approved plan")
+
+	modeCmd.AddCommand(modeHistoryCmd)
 }
 
-// getWorkspaceName returns the workspace name from flag or environment.
+// getWorkspaceName returns the workspace name from flag, environment, or the
+// workspace metadata of the current directory.
 func getWorkspaceName() (string, error) {
 	if modeWorkspace != "" {
 		return modeWorkspace, nil
@@ -47,7 +84,14 @@ func getWorkspaceName() (string, error) {
 		return name, nil
 	}
 
-	return "", fmt.Errorf("workspace name required: use --workspace flag or set PLANQ_WORKSPACE")
+	// Try the workspace we are standing in
+	if cwd, err := os.Getwd(); err == nil {
+		if meta, _, err := workspace.FindMetadata(cwd); err == nil && meta != nil {
+			return meta.Name, nil
+		}
+	}
+
+	return "", fmt.Errorf("workspace name required: use --workspace flag, set PLANQ_WORKSPACE or run inside a workspace")
 }
 
 // getTmuxSessionEnv reads an environment variable from a tmux session.
@@ -67,43 +111,6 @@ func getTmuxSessionEnv(sessionName, varName string) (string, error) {
 	return parts[1], nil
 }
 
-// getWorktreePath returns the worktree path from flag, tmux session env, process env, or cwd.
-func getWorktreePath(workspaceName string) (string, error) {
-	// First, check the flag
-	if modeWorktree != "" {
-		return modeWorktree, nil
-	}
-
-	// Second, check tmux session environment variable
-	sessionName := sessionPrefix + workspaceName
-	if path, err := getTmuxSessionEnv(sessionName, "PLANQ_WORKTREE_PATH"); err == nil && path != "" {
-		return path, nil
-	}
-
-	// Third, check process environment variable
-	if path := os.Getenv("PLANQ_WORKTREE_PATH"); path != "" {
-		return path, nil
-	}
-
-	// Finally, fall back to current working directory
-	return os.Getwd()
-}
-
-// loadWorkspace loads a workspace by name.
-func loadWorkspace(name string) (*workspace.Workspace, string, error) {
-	workdir, err := getWorktreePath(name)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to get worktree path: %w", err)
-	}
-
-	ws := &workspace.Workspace{
-		Name:         name,
-		WorktreePath: workdir,
-	}
-
-	return ws, workdir, nil
-}
-
 // showMode displays the current workspace mode and reapplies the layout.
 func showMode() error {
 	name, err := getWorkspaceName()
@@ -111,7 +118,7 @@ func showMode() error {
 		return err
 	}
 
-	ws, workdir, err := loadWorkspace(name)
+	ws, meta, err := findWorkspace(name)
 	if err != nil {
 		return err
 	}
@@ -124,7 +131,7 @@ func showMode() error {
 	fmt.Printf("Workspace %q is in %s mode\n", name, mode)
 
 	// Always reapply layout in case the view is messed up
-	return reconfigureSession(name, workdir, ws, mode)
+	return reconfigureSession(os.Stdout, ws, meta, mode)
 }
 
 // switchMode switches to the specified mode or toggles.
@@ -134,27 +141,34 @@ func switchMode(target string) error {
 		return err
 	}
 
-	ws, workdir, err := loadWorkspace(name)
+	ws, meta, err := findWorkspace(name)
 	if err != nil {
 		return err
 	}
 
-	var newMode workspace.Mode
+	trigger, err := workspace.ParseTrigger(modeTrigger)
+	if err != nil {
+		return err
+	}
+	if modeForce {
+		if err := requireHuman("skipping the plan approval check", name); err != nil {
+			return err
+		}
+	}
+	opts := workspace.SwitchOptions{Trigger: trigger, Force: modeForce}
 
-	switch target {
-	case "plan":
-		newMode = workspace.ModePlan
-	case "execute":
-		newMode = workspace.ModeExecute
-	case "toggle":
-		newMode, err = ws.ToggleMode()
+	if target == "toggle" {
+		newMode, err := ws.ToggleMode(opts)
 		if err != nil {
 			return fmt.Errorf("failed to toggle mode: %w", err)
 		}
 		fmt.Printf("Switched workspace %q to %s mode\n", name, newMode)
-		return reconfigureSession(name, workdir, ws, newMode)
-	default:
-		return fmt.Errorf("invalid mode %q: use 'plan', 'execute', or 'toggle'", target)
+		return reconfigureSession(os.Stdout, ws, meta, newMode)
+	}
+
+	newMode := workspace.Mode(target)
+	if _, err := workspace.LookupMode(newMode); err != nil {
+		return fmt.Errorf("invalid mode %q: use one of %s, or 'toggle'", target, strings.Join(workspace.ModeNames(), ", "))
 	}
 
 	// Check if already in target mode
@@ -167,18 +181,58 @@ func switchMode(target string) error {
 		fmt.Printf("Workspace %q is already in %s mode\n", name, newMode)
 	} else {
 		// Set the new mode
-		if err := ws.SetMode(newMode); err != nil {
+		if err := ws.SetMode(newMode, opts); err != nil {
 			return fmt.Errorf("failed to set mode: %w", err)
 		}
 		fmt.Printf("Switched workspace %q to %s mode\n", name, newMode)
 	}
 
 	// Always reapply layout in case the view is messed up
-	return reconfigureSession(name, workdir, ws, newMode)
+	return reconfigureSession(os.Stdout, ws, meta, newMode)
 }
 
-// reconfigureSession reconfigures the tmux session for the new mode.
-func reconfigureSession(name, workdir string, ws *workspace.Workspace, mode workspace.Mode) error {
+// showModeHistory prints the mode transition history of a workspace.
+func showModeHistory() error {
+	name, err := getWorkspaceName()
+	if err != nil {
+		return err
+	}
+
+	ws, _, err := findWorkspace(name)
+	if err != nil {
+		return err
+	}
+
+	history, err := ws.ModeHistory()
+	if err != nil {
+		return fmt.Errorf("failed to read mode history: %w", err)
+	}
+
+	if len(history) == 0 {
+		fmt.Printf("No mode transitions recorded for workspace %q\n", name)
+		return nil
+	}
+
+	for _, t := range history {
+		planHash := t.PlanHash
+		if len(planHash) > 12 {
+			planHash = planHash[:12]
+		}
+		if planHash == "" {
+			planHash = "-"
+		}
+		fmt.Printf("%s  %-7s -> %-7s  %-10s  plan %s\n",
+			t.At.Local().Format("2006-01-02 15:04:05"), t.From, t.To, t.Trigger, planHash)
+	}
+
+	return nil
+}
+
+// reconfigureSession reconfigures the tmux session for the new mode and
+// reports what it did to out.
+func reconfigureSession(out io.Writer, ws *workspace.Workspace, meta *workspace.Metadata, mode workspace.Mode) error {
+	name := ws.Name
+	workdir := ws.WorktreePath
 	sessionName := sessionPrefix + name
 
 	tm, err := tmux.NewManager()
@@ -199,36 +253,47 @@ func reconfigureSession(name, workdir string, ws *workspace.Workspace, mode work
 		}
 	}
 
-	// Get the appropriate layout for the mode
-	agentCmd := ws.AgentCommand()
-	var layout tmux.Layout
+	cfg, err := config.Load(workdir)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
 
-	switch mode {
-	case workspace.ModeExecute:
-		layout = tmux.ExecuteLayout(agentCmd)
-	default:
-		layout = tmux.PlanLayout(agentCmd, planFile)
+	// Pick the agent for this mode, honoring the workspace's agent choice
+	activeAgent, _ := getTmuxSessionEnv(sessionName, "PLANQ_ACTIVE_AGENT")
+	configureAgent(cfg, ws, mode, meta.Agent)
+
+	// Get the appropriate layout for the mode
+	agentCmd, err := agentCommand(ws, meta)
+	if err != nil {
+		return err
 	}
+	layout := modeLayout(cfg, ws, mode, agentCmd)
 
 	changed, err := tm.ReconfigureSession(sessionName, workdir, layout)
 	if err != nil {
 		return fmt.Errorf("failed to reconfigure session: %w", err)
 	}
 
+	// Restart the agent pane if this mode runs a different agent
+	if activeAgent != "" && activeAgent != ws.AgentName {
+		fmt.Fprintf(out, "Switching agent from %s to %s\n", activeAgent, ws.AgentName)
+		if err := tm.RespawnPane(sessionName, 0, workdir, agentCmd); err != nil {
+			return fmt.Errorf("failed to restart agent pane: %w", err)
+		}
+		changed = true
+	}
+	if err := tm.SetEnvironment(sessionName, "PLANQ_ACTIVE_AGENT", ws.AgentName); err != nil {
+		fmt.Fprintf(out, "Warning: could not record active agent: %v\n", err)
+	}
+
 	// Update status bar with current mode
-	if err := tm.ConfigureStatusBar(sessionName, name, string(mode)); err != nil {
+	if err := tm.ConfigureStatusBar(sessionName, name, workdir, string(mode), modeStatusColor(mode)); err != nil {
 		// Non-fatal, just warn
-		fmt.Printf("Warning: could not update status bar: %v\n", err)
+		fmt.Fprintf(out, "Warning: could not update status bar: %v\n", err)
 	}
 
 	// Set pane titles based on mode
-	var paneTitles []string
-	if mode == workspace.ModeExecute {
-		paneTitles = []string{"Agent"}
-	} else {
-		paneTitles = []string{"Agent", "Plan", "Terminal"}
-	}
-	for i, title := range paneTitles {
+	for i, title := range modePaneTitles(cfg, mode) {
 		if err := tm.SetPaneTitle(sessionName, i, title); err != nil {
 			// Non-fatal, pane might not exist yet
 			break
@@ -236,9 +301,9 @@ func reconfigureSession(name, workdir string, ws *workspace.Workspace, mode work
 	}
 
 	if changed {
-		fmt.Printf("Reconfigured tmux session for %s mode\n", mode)
+		fmt.Fprintf(out, "Reconfigured tmux session for %s mode\n", mode)
 	} else {
-		fmt.Printf("Layout already matches %s mode, no changes needed\n", mode)
+		fmt.Fprintf(out, "Layout already matches %s mode, no changes needed\n", mode)
 	}
 
 	return nil
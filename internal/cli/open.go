@@ -6,8 +6,7 @@ import (
 	"os/exec"
 
 	"github.com/spf13/cobra"
-	"planq.dev/planq/internal/stackit"
-	"planq.dev/planq/internal/state"
+	"planq.dev/planq/internal/config"
 	"planq.dev/planq/internal/tmux"
 	"planq.dev/planq/internal/workspace"
 )
@@ -15,8 +14,11 @@ import (
 var openCmd = &cobra.Command{
 	Use:   "open <name>",
 	Short: "Open an existing workspace",
-	Long:  `Open an existing workspace by attaching to its tmux session.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Open an existing workspace by attaching to its tmux session.
+
+If the session is gone but the worktree still exists, the session is
+recreated using the layout from .planq/config.yaml.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return openWorkspace(args[0])
 	},
@@ -36,7 +38,32 @@ func openWorkspace(name string) error {
 		return fmt.Errorf("failed to check session: %w", err)
 	}
 	if !exists {
-		return fmt.Errorf("workspace %q does not exist", name)
+		// Rebuild the session if the worktree is still around
+		ws, meta, err := findWorkspace(name)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.Load(ws.WorktreePath)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		mode, err := ws.GetMode()
+		if err != nil {
+			mode = workspace.ModePlan
+		}
+		configureAgent(cfg, ws, mode, meta.Agent)
+
+		agentCmd, err := agentCommand(ws, meta)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Recreating tmux session %q...\n", sessionName)
+		if err := startSession(tm, ws, cfg, agentCmd, meta.Agent); err != nil {
+			return fmt.Errorf("failed to create tmux session: %w", err)
+		}
 	}
 
 	// Clear review flag before attaching
@@ -66,28 +93,22 @@ func execCommand(name string, args ...string) error {
 // clearReviewFlag clears the needs review flag for a workspace.
 // Silently fails if workspace path cannot be determined.
 func clearReviewFlag(name string) {
-	var workdir string
-
-	// Try to get worktree path from stackit
-	st := stackit.NewClient()
-	if path, err := st.WorktreeOpen(name); err == nil {
-		workdir = path
-	} else {
-		// Try to get from global state (main workspace)
-		if globalState, err := state.Load(); err == nil {
-			if repoPath, exists := globalState.FindMainWorkspaceByName(name); exists {
-				workdir = repoPath
-			}
-		}
-	}
-
-	if workdir == "" {
+	ws, _, err := findWorkspace(name)
+	if err != nil {
 		return
 	}
+	_ = ws.ClearReview()
+}
 
-	ws := &workspace.Workspace{
-		Name:         name,
-		WorktreePath: workdir,
+// agentCommand returns the agent pane command of a workspace: the one given
+// with --agent-cmd at creation, or the agent's default.
+func agentCommand(ws *workspace.Workspace, meta *workspace.Metadata) (string, error) {
+	if meta.AgentCommand != "" {
+		return meta.AgentCommand, nil
 	}
-	_ = ws.ClearReview()
+	agentCmd, err := ws.AgentCommand()
+	if err != nil {
+		return "", fmt.Errorf("failed to build agent command: %w", err)
+	}
+	return agentCmd, nil
 }
@@ -0,0 +1,337 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"planq.dev/planq/internal/config"
+	"planq.dev/planq/internal/git"
+	"planq.dev/planq/internal/plan"
+	"planq.dev/planq/internal/workspace"
+)
+
+var planApproveBy string
+var planProgressStatus bool
+
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Manage workspace plans",
+}
+
+var planApproveCmd = &cobra.Command{
+	Use:   "approve [name]",
+	Short: "Approve the workspace plan",
+	Long: `Approve the current plan of a workspace so it can enter execute mode.
+
+Records the approver and a hash of the plan file. If the plan changes
+after approval, it must be approved again before switching to execute mode.
+
+The approver defaults to git user.name, falling back to $USER. The approval
+records which of --by, git or $USER the name came from.
+
+Approving must be done by a person: the command refuses to run without a
+terminal, and inside a workspace session it asks for the workspace name.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return approvePlan(args)
+	},
+}
+
+var planLogCmd = &cobra.Command{
+	Use:   "log [name]",
+	Short: "List saved plan revisions",
+	Long: `List the plan snapshots saved in .planq/artifacts/plans/.
+
+A snapshot is taken every time the plan is approved and every time the
+workspace switches into execute mode.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showPlanLog(args)
+	},
+}
+
+var planDiffCmd = &cobra.Command{
+	Use:   "diff [a] [b]",
+	Short: "Show how the plan changed between revisions",
+	Long: `Show the diff between two plan revisions.
+
+Revisions are referenced by their number in 'planq plan log' or by their ID.
+Use 'current' for the plan file as it is now.
+
+Without arguments, compares the latest snapshot with the current plan.
+With one argument, compares that revision with the current plan.`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return diffPlan(args)
+	},
+}
+
+var planProgressCmd = &cobra.Command{
+	Use:   "progress [name]",
+	Short: "Show plan checklist progress",
+	Long:  `Show how many checklist steps ("- [ ]" / "- [x]") in the plan are done.`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showPlanProgress(args)
+	},
+}
+
+var planTemplatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "List plan templates for planq create --template",
+	Long: `List the plan templates available to 'planq create --template'.
+
+Templates are looked up in .planq/templates/ in the repository, then in
+the user config directory (~/.config/planq/templates/), then among the
+built-in templates.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listPlanTemplates()
+	},
+}
+
+func init() {
+	planCmd.PersistentFlags().StringVarP(&modeWorkspace, "workspace", "w", "", "Workspace name (default: detect from environment)")
+	planCmd.PersistentFlags().StringVar(&modeWorktree, "worktree", "", "Worktree path (default: look the workspace up in the registry)")
+	planProgressCmd.Flags().BoolVar(&planProgressStatus, "status", false, "Print a status bar segment (empty when the plan has no steps)")
+	_ = planProgressCmd.Flags().MarkHidden("status")
+	planApproveCmd.Flags().StringVar(&planApproveBy, "by", "", "Name of the approver (default: git user.name)")
+
+	planCmd.AddCommand(planApproveCmd)
+	planCmd.AddCommand(planLogCmd)
+	planCmd.AddCommand(planDiffCmd)
+	planCmd.AddCommand(planProgressCmd)
+	planCmd.AddCommand(planTemplatesCmd)
+}
+
+// planWorkspace loads the workspace named in args, or the current workspace.
+func planWorkspace(args []string) (*workspace.Workspace, error) {
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	} else {
+		var err error
+		name, err = getWorkspaceName()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ws, _, err := findWorkspace(name)
+	return ws, err
+}
+
+// approver returns the name to record for a plan approval and where it came from.
+func approver() (string, workspace.ApprovalSource, error) {
+	if planApproveBy != "" {
+		return planApproveBy, workspace.ApprovalSourceFlag, nil
+	}
+	if name, err := git.GetUserName(); err == nil && name != "" {
+		return name, workspace.ApprovalSourceGit, nil
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name, workspace.ApprovalSourceEnv, nil
+	}
+	return "", "", fmt.Errorf("could not determine the approver: use --by")
+}
+
+// requireHuman refuses action unless a person runs it from a terminal. Agents
+// run commands without one. Inside a workspace session, where an agent may
+// still reach a terminal, the person confirms by typing the workspace name.
+func requireHuman(action, name string) error {
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return fmt.Errorf("%s must be run by a person in a terminal", action)
+	}
+	if os.Getenv("PLANQ_WORKSPACE") == "" {
+		return nil
+	}
+
+	fmt.Printf("This runs inside workspace %q, where agents work.\nType the workspace name to confirm %s: ", name, action)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.TrimSpace(answer) != name {
+		return fmt.Errorf("%s not confirmed", action)
+	}
+	return nil
+}
+
+// approvePlan records approval of a workspace plan.
+func approvePlan(args []string) error {
+	ws, err := planWorkspace(args)
+	if err != nil {
+		return err
+	}
+	if err := requireHuman("plan approval", ws.Name); err != nil {
+		return err
+	}
+
+	by, source, err := approver()
+	if err != nil {
+		return err
+	}
+	approval, err := ws.ApprovePlan(by, source)
+	if err != nil {
+		return fmt.Errorf("failed to approve plan: %w", err)
+	}
+
+	fmt.Printf("Approved plan for workspace %q (by %s from %s, plan %s)\n", ws.Name, approval.ApprovedBy, approval.Source, approval.PlanHash[:12])
+	return nil
+}
+
+// showPlanLog lists the plan snapshots of a workspace.
+func showPlanLog(args []string) error {
+	ws, err := planWorkspace(args)
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := ws.PlanSnapshots()
+	if err != nil {
+		return err
+	}
+
+	if len(snapshots) == 0 {
+		fmt.Printf("No plan revisions saved for workspace %q\n", ws.Name)
+		return nil
+	}
+
+	for i, snapshot := range snapshots {
+		fmt.Printf("%3d  %s  %-8s  %s\n", i+1, snapshot.CreatedAt.Format("2006-01-02 15:04:05"), snapshot.Reason, snapshot.ID)
+	}
+
+	return nil
+}
+
+// planRevisionPath returns the file for a plan revision reference.
+func planRevisionPath(ws *workspace.Workspace, ref string) (string, error) {
+	if ref == "current" {
+		return ws.PlanFile(), nil
+	}
+
+	snapshot, err := ws.FindPlanSnapshot(ref)
+	if err != nil {
+		return "", err
+	}
+	return snapshot.Path, nil
+}
+
+// diffPlan shows the diff between two plan revisions.
+func diffPlan(args []string) error {
+	ws, err := planWorkspace(nil)
+	if err != nil {
+		return err
+	}
+
+	from, to := "", "current"
+	switch len(args) {
+	case 0:
+		snapshots, err := ws.PlanSnapshots()
+		if err != nil {
+			return err
+		}
+		if len(snapshots) == 0 {
+			return fmt.Errorf("no plan revisions saved for workspace %q", ws.Name)
+		}
+		from = snapshots[len(snapshots)-1].ID
+	case 1:
+		from = args[0]
+	default:
+		from, to = args[0], args[1]
+	}
+
+	fromPath, err := planRevisionPath(ws, from)
+	if err != nil {
+		return err
+	}
+	toPath, err := planRevisionPath(ws, to)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("git", "diff", "--no-index", "--", fromPath, toPath)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// git diff exits 1 when the files differ
+	var exitErr *exec.ExitError
+	if err := cmd.Run(); err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return fmt.Errorf("failed to diff plan revisions: %w", err)
+	}
+
+	return nil
+}
+
+// showPlanProgress prints the plan checklist progress of a workspace.
+func showPlanProgress(args []string) error {
+	if planProgressStatus {
+		printPlanStatus()
+		return nil
+	}
+
+	ws, err := planWorkspace(args)
+	if err != nil {
+		return err
+	}
+
+	steps, err := plan.ParseFile(ws.PlanFile())
+	if err != nil {
+		return err
+	}
+	progress := plan.Summarize(steps)
+
+	if progress.Total == 0 {
+		fmt.Printf("Plan for workspace %q has no checklist steps\n", ws.Name)
+		return nil
+	}
+
+	fmt.Printf("Plan for workspace %q: %s\n", ws.Name, progress)
+	for _, step := range steps {
+		mark := " "
+		if step.Done {
+			mark = "x"
+		}
+		fmt.Printf("%3d. [%s] %s\n", step.Number, mark, step.Text)
+	}
+
+	return nil
+}
+
+// printPlanStatus prints the status bar segment for the plan in --worktree.
+// tmux runs it on every redraw, so it reads the plan file directly without
+// looking the workspace up, and prints nothing on failure.
+func printPlanStatus() {
+	if modeWorkspace == "" || modeWorktree == "" {
+		return
+	}
+	ws := &workspace.Workspace{Name: modeWorkspace, WorktreePath: modeWorktree}
+	steps, err := plan.ParseFile(ws.PlanFile())
+	if err != nil {
+		return
+	}
+	if progress := plan.Summarize(steps); progress.Total > 0 {
+		fmt.Printf(" │ %s", progress)
+	}
+}
+
+// listPlanTemplates prints the available plan template names.
+func listPlanTemplates() error {
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return fmt.Errorf("failed to get repository root: %w", err)
+	}
+
+	dirs, err := config.TemplateDirs(repoRoot)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range plan.TemplateNames(dirs...) {
+		fmt.Println(name)
+	}
+	return nil
+}
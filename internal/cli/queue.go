@@ -2,39 +2,490 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
-	"planq.dev/planq/internal/git"
 	"planq.dev/planq/internal/queue"
 )
 
+var (
+	queueTitle    string
+	queuePriority int
+	queueTags     []string
+	queueStatus   string
+	queueSource   string
+	queueAll      bool
+
+	queueStartName   string
+	queueStartAgent  string
+	queueStartPrompt bool
+	queueStartDetach bool
+)
+
 var queueCmd = &cobra.Command{
 	Use:   "queue <text>",
 	Short: "Queue work for later",
 	Long: `Queue a plan, bug, or idea to revisit later.
 
-Items are saved to .planq/queue/ as timestamped markdown files.`,
+Items are saved to .planq/queue/ as markdown files with a YAML frontmatter
+header (id, title, priority, tags, status, source workspace, created_at).
+Priorities range from 1 (highest) to 5 (lowest).
+
+'planq queue <text>' is short for 'planq queue add <text>' when the text does
+not start with a subcommand name. Text such as "list the failing tests" runs
+that subcommand instead, so queue it with 'planq queue add'.`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		text := strings.Join(args, " ")
-		return runQueue(text)
+		return runQueue(strings.Join(args, " "))
+	},
+}
+
+var queueAddCmd = &cobra.Command{
+	Use:   "add <text>",
+	Short: "Add an item to the queue",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runQueue(strings.Join(args, " "))
+	},
+}
+
+var queueListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List queued items",
+	Long: `List queued items, most urgent first.
+
+Only open items are shown unless --status or --all is given.`,
+	Args: queueArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listQueue()
+	},
+}
+
+var queueShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a queued item",
+	Args:  queueArgs(cobra.ExactArgs(1), 1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showQueueItem(args[0])
+	},
+}
+
+var queueEditCmd = &cobra.Command{
+	Use:   "edit <id>",
+	Short: "Edit a queued item in $EDITOR",
+	Args:  queueArgs(cobra.ExactArgs(1), 1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return editQueueItem(args[0])
 	},
 }
 
+var queueDoneCmd = &cobra.Command{
+	Use:   "done <id>...",
+	Short: "Mark queued items as done",
+	Args:  queueArgs(cobra.MinimumNArgs(1), -1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return eachQueueItem(args, func(root string, id int) error {
+			item, err := queue.SetStatus(root, id, queue.StatusDone)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Done: #%d %s\n", item.ID, item.Title)
+			return nil
+		})
+	},
+}
+
+var queueRmCmd = &cobra.Command{
+	Use:   "rm <id>...",
+	Short: "Remove queued items",
+	Args:  queueArgs(cobra.MinimumNArgs(1), -1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return eachQueueItem(args, func(root string, id int) error {
+			item, err := queue.Remove(root, id)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Removed: #%d %s\n", item.ID, item.Title)
+			return nil
+		})
+	},
+}
+
+var queueStartCmd = &cobra.Command{
+	Use:   "start <id>",
+	Short: "Create a workspace for a queued item",
+	Long: `Create a workspace to work on a queued item.
+
+The item's content seeds the workspace's plan file, or with --prompt is sent
+to the agent as its first message. The item is claimed by the workspace and
+archived when the workspace is removed. Use 'planq remove --abandon' or
+'planq queue reopen' to put it back in the queue instead.`,
+	Args: queueArgs(cobra.ExactArgs(1), 1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return startQueueItem(args[0])
+	},
+}
+
+var queueReopenCmd = &cobra.Command{
+	Use:   "reopen <id>...",
+	Short: "Put claimed, done or archived items back in the queue",
+	Args:  queueArgs(cobra.MinimumNArgs(1), -1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return eachQueueItem(args, func(root string, id int) error {
+			item, err := queue.Reopen(root, id)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Reopened: #%d %s\n", item.ID, item.Title)
+			return nil
+		})
+	},
+}
+
+var queueReprioritizeCmd = &cobra.Command{
+	Use:   "reprioritize <id> <priority>",
+	Short: "Change the priority of a queued item",
+	Args:  queueArgs(cobra.ExactArgs(2), 1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return reprioritizeQueueItem(args[0], args[1])
+	},
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{queueCmd, queueAddCmd} {
+		cmd.Flags().StringVar(&queueTitle, "title", "", "Item title (default: first line of the text)")
+		cmd.Flags().IntVarP(&queuePriority, "priority", "p", queue.DefaultPriority, "Priority from 1 (highest) to 5 (lowest)")
+		cmd.Flags().StringSliceVarP(&queueTags, "tag", "t", nil, "Tag the item (repeatable)")
+	}
+
+	queueListCmd.Flags().StringVar(&queueStatus, "status", string(queue.StatusOpen), "Only show items with this status")
+	queueListCmd.Flags().BoolVarP(&queueAll, "all", "a", false, "Show items of every status")
+	queueListCmd.Flags().StringSliceVarP(&queueTags, "tag", "t", nil, "Only show items with this tag (repeatable)")
+	queueListCmd.Flags().StringVar(&queueSource, "source", "", "Only show items queued from this workspace")
+
+	queueStartCmd.Flags().StringVarP(&queueStartName, "name", "n", "", "Workspace name (default: derived from the item title)")
+	queueStartCmd.Flags().StringVar(&queueStartAgent, "agent", "", "Agent backend (default: agent.name from config)")
+	queueStartCmd.Flags().BoolVar(&queueStartPrompt, "prompt", false, "Send the item to the agent as its first message instead of seeding the plan")
+	queueStartCmd.Flags().BoolVarP(&queueStartDetach, "detach", "d", false, "Create the workspace without opening it")
+
+	queueCmd.AddCommand(queueAddCmd)
+	queueCmd.AddCommand(queueListCmd)
+	queueCmd.AddCommand(queueShowCmd)
+	queueCmd.AddCommand(queueEditCmd)
+	queueCmd.AddCommand(queueDoneCmd)
+	queueCmd.AddCommand(queueRmCmd)
+	queueCmd.AddCommand(queueReprioritizeCmd)
+	queueCmd.AddCommand(queueStartCmd)
+	queueCmd.AddCommand(queueReopenCmd)
+}
+
+// queueArgs checks a queue subcommand's arguments with check and, when ids is
+// not zero, that its first ids arguments (all of them when negative) are
+// item IDs. Queue text that starts with the subcommand's name ends up here,
+// so errors point at 'planq queue add'.
+func queueArgs(check cobra.PositionalArgs, ids ...int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		err := check(cmd, args)
+		if err == nil && len(ids) > 0 {
+			n := ids[0]
+			if n < 0 || n > len(args) {
+				n = len(args)
+			}
+			for _, arg := range args[:n] {
+				if _, err = queue.ParseID(arg); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("%w\nTo queue text that starts with %q, use 'planq queue add <text>'", err, cmd.Name())
+		}
+		return nil
+	}
+}
+
+// queueRoot returns the project whose queue the CLI works on.
+func queueRoot() (string, error) {
+	projectRoot, err := getProjectRoot()
+	if err != nil {
+		return "", fmt.Errorf("failed to find project root: %w", err)
+	}
+	return projectRoot, nil
+}
+
 func runQueue(text string) error {
-	// Get project root
-	projectRoot, err := git.GetRepoRoot()
+	projectRoot, err := queueRoot()
+	if err != nil {
+		return err
+	}
+
+	item, err := queue.Add(projectRoot, text, queue.AddOptions{
+		Title:    queueTitle,
+		Priority: queuePriority,
+		Tags:     queueTags,
+		Source:   os.Getenv("PLANQ_WORKSPACE"),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Queued #%d: %s\n", item.ID, queue.Path(projectRoot, item))
+	return nil
+}
+
+// listQueue prints the queued items matching the list flags.
+func listQueue() error {
+	projectRoot, err := queueRoot()
+	if err != nil {
+		return err
+	}
+
+	filter := queue.Filter{Tags: queueTags, Source: queueSource}
+	if !queueAll {
+		status, err := queue.ParseStatus(queueStatus)
+		if err != nil {
+			return err
+		}
+		filter.Status = status
+	}
+
+	items, err := queue.List(projectRoot, filter)
+	if err != nil {
+		return err
+	}
+
+	if len(items) == 0 {
+		fmt.Println("No items in queue")
+		return nil
+	}
+
+	for _, item := range items {
+		tags := ""
+		if len(item.Tags) > 0 {
+			tags = "  [" + strings.Join(item.Tags, ", ") + "]"
+		}
+		workspace := ""
+		if item.Workspace != "" {
+			workspace = "  (" + item.Workspace + ")"
+		}
+		fmt.Printf("#%-4d P%d  %-8s  %s%s%s\n", item.ID, item.Priority, item.Status, item.Title, tags, workspace)
+	}
+	return nil
+}
+
+// showQueueItem prints an item's fields and body.
+func showQueueItem(ref string) error {
+	projectRoot, id, err := queueItemRef(ref)
 	if err != nil {
-		return fmt.Errorf("failed to find project root: %w", err)
+		return err
 	}
 
-	// Add to queue
-	filePath, err := queue.Add(projectRoot, text)
+	item, err := queue.Get(projectRoot, id)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Queued: %s\n", filePath)
+	fmt.Printf("#%d %s\n\n", item.ID, item.Title)
+	fmt.Printf("Priority: %d\n", item.Priority)
+	fmt.Printf("Status:   %s\n", item.Status)
+	if len(item.Tags) > 0 {
+		fmt.Printf("Tags:     %s\n", strings.Join(item.Tags, ", "))
+	}
+	if item.Source != "" {
+		fmt.Printf("Source:   %s\n", item.Source)
+	}
+	if item.Workspace != "" {
+		fmt.Printf("Claimed:  %s\n", item.Workspace)
+	}
+	if item.Lease != nil && !item.Lease.ExpiresAt.IsZero() {
+		fmt.Printf("Lease:    until %s\n", item.Lease.ExpiresAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	fmt.Printf("Created:  %s\n", item.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf("File:     %s\n", queue.Path(projectRoot, item))
+	if item.Body != "" {
+		fmt.Printf("\n%s\n", item.Body)
+	}
 	return nil
 }
+
+// editQueueItem opens an item in the user's editor and checks the result.
+func editQueueItem(ref string) error {
+	projectRoot, id, err := queueItemRef(ref)
+	if err != nil {
+		return err
+	}
+
+	item, err := queue.Get(projectRoot, id)
+	if err != nil {
+		return err
+	}
+	path := queue.Path(projectRoot, item)
+
+	if err := runEditor(path); err != nil {
+		return err
+	}
+
+	if err := queue.Validate(path); err != nil {
+		return fmt.Errorf("%s is no longer a valid queue item: %w", path, err)
+	}
+	return nil
+}
+
+// runEditor opens path in $VISUAL or $EDITOR, falling back to vi.
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// Run through the shell so editors with arguments ("code --wait") work
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor failed: %w", err)
+	}
+	return nil
+}
+
+// startQueueItem creates a workspace that claims a queued item.
+func startQueueItem(ref string) error {
+	projectRoot, id, err := queueItemRef(ref)
+	if err != nil {
+		return err
+	}
+
+	item, err := queue.Get(projectRoot, id)
+	if err != nil {
+		return err
+	}
+	if item.Status != queue.StatusOpen {
+		return fmt.Errorf("queue item #%d is %s, use 'planq queue reopen %d' first", item.ID, item.Status, item.ID)
+	}
+
+	name := queueStartName
+	if name == "" {
+		name = workspaceNameFor(item)
+	}
+
+	opts := createOptions{
+		Agent:     queueStartAgent,
+		Detach:    queueStartDetach,
+		QueueItem: item.ID,
+		QueueRoot: projectRoot,
+	}
+	if queueStartPrompt {
+		opts.InitialPrompt = queueItemPrompt(item)
+	} else {
+		opts.Plan = queueItemPlan(item)
+	}
+
+	return createWorkspace(name, opts)
+}
+
+// queueItemPrompt asks the agent to work on a queue item.
+func queueItemPrompt(item *queue.Item) string {
+	return fmt.Sprintf("Work on queued item #%d: %s\n\n%s", item.ID, item.Title, item.Body)
+}
+
+// queueItemPlan renders a queue item as the initial plan file.
+func queueItemPlan(item *queue.Item) []byte {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", item.Title)
+	body := item.Body
+	if first, rest, _ := strings.Cut(body, "\n"); strings.TrimSpace(strings.TrimLeft(first, "# ")) == item.Title {
+		body = strings.TrimSpace(rest) // the title is the first line of the body
+	}
+	if body != "" {
+		fmt.Fprintf(&sb, "%s\n\n", body)
+	}
+	fmt.Fprintf(&sb, "_Started from queue item #%d._\n", item.ID)
+	return []byte(sb.String())
+}
+
+// workspaceNameFor derives a workspace name from a queue item's title.
+func workspaceNameFor(item *queue.Item) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(item.Title) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			sb.WriteRune(r)
+			dash = false
+		case !dash && sb.Len() > 0:
+			sb.WriteRune('-')
+			dash = true
+		}
+		if sb.Len() >= 40 {
+			break
+		}
+	}
+	name := strings.Trim(sb.String(), "-")
+	if name == "" {
+		return fmt.Sprintf("queue-%d", item.ID)
+	}
+	return name
+}
+
+// reprioritizeQueueItem changes an item's priority.
+func reprioritizeQueueItem(ref, priority string) error {
+	projectRoot, id, err := queueItemRef(ref)
+	if err != nil {
+		return err
+	}
+
+	p, err := strconv.Atoi(priority)
+	if err != nil {
+		return fmt.Errorf("invalid priority %q", priority)
+	}
+
+	item, err := queue.SetPriority(projectRoot, id, p)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("#%d %s is now priority %d\n", item.ID, item.Title, item.Priority)
+	return nil
+}
+
+// queueItemRef resolves the project root and item ID for a command argument.
+func queueItemRef(ref string) (string, int, error) {
+	id, err := queue.ParseID(ref)
+	if err != nil {
+		return "", 0, err
+	}
+	projectRoot, err := queueRoot()
+	if err != nil {
+		return "", 0, err
+	}
+	return projectRoot, id, nil
+}
+
+// eachQueueItem runs fn for every item ID in args, reporting the first error.
+func eachQueueItem(args []string, fn func(projectRoot string, id int) error) error {
+	projectRoot, err := queueRoot()
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, arg := range args {
+		id, err := queue.ParseID(arg)
+		if err == nil {
+			err = fn(projectRoot, id)
+		}
+		if err != nil {
+			fmt.Printf("  Warning: %v\n", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"planq.dev/planq/internal/queue"
+)
+
+func TestQueueTextStartingWithSubcommand(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("PLANQ_PROJECT_ROOT", root)
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+	defer rootCmd.SetArgs(nil)
+
+	run := func(args ...string) error {
+		rootCmd.SetArgs(append([]string{"queue"}, args...))
+		return rootCmd.Execute()
+	}
+
+	// Text that starts with a subcommand name runs the subcommand and
+	// points at 'planq queue add'
+	for _, args := range [][]string{
+		{"list", "the", "failing", "tests"},
+		{"done", "with", "the", "refactor"},
+		{"reprioritize", "the", "backlog"},
+	} {
+		err := run(args...)
+		if err == nil || !strings.Contains(err.Error(), "planq queue add") {
+			t.Errorf("queue %s error = %v, want a hint to use 'planq queue add'", strings.Join(args, " "), err)
+		}
+	}
+	if items, err := queue.List(root, queue.Filter{}); err != nil || len(items) != 0 {
+		t.Fatalf("List() = %v, %v; want no items", items, err)
+	}
+
+	if err := run("add", "list", "the", "failing", "tests"); err != nil {
+		t.Fatalf("queue add error = %v", err)
+	}
+	if err := run("fix", "the", "login", "page"); err != nil {
+		t.Fatalf("queue error = %v", err)
+	}
+
+	items, err := queue.List(root, queue.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, item := range items {
+		got = append(got, strings.TrimSpace(item.Body))
+	}
+	want := "list the failing tests|fix the login page"
+	if strings.Join(got, "|") != want {
+		t.Errorf("queued %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"planq.dev/planq/internal/queue"
+)
+
+var (
+	queueImportFormat string
+
+	queueExportFormat string
+	queueExportStatus string
+	queueExportOutput string
+)
+
+var queueImportCmd = &cobra.Command{
+	Use:   "import <file|dir|->...",
+	Short: "Import items from JSONL, markdown task lists, Beads or Tasuku",
+	Long: `Split backlog files into queue items.
+
+Supported formats:
+  jsonl   one JSON object per line, as written by 'planq queue export'
+  md      a markdown task list, one item per "- [ ]" task
+  beads   a Beads issue log (.beads/issues.jsonl)
+  tasuku  a Tasuku task directory (.tasuku/)
+
+The format is detected from the file name and location unless --format is
+given. Use - to read from stdin. Items whose title and text are already in the
+queue are skipped.`,
+	Args: queueArgs(cobra.MinimumNArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return importQueue(args)
+	},
+}
+
+var queueExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export queued items as JSONL or a markdown task list",
+	Long: `Write queued items, most urgent first, as JSONL or a markdown task list.
+
+Items of every status are exported unless --status is given.`,
+	Args: queueArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return exportQueue()
+	},
+}
+
+func init() {
+	queueImportCmd.Flags().StringVarP(&queueImportFormat, "format", "f", "", fmt.Sprintf("Input format %v (default: detected)", queue.ImportFormats))
+	queueImportCmd.Flags().StringSliceVarP(&queueTags, "tag", "t", nil, "Tag every imported item (repeatable)")
+
+	queueExportCmd.Flags().StringVarP(&queueExportFormat, "format", "f", string(queue.FormatJSONL), fmt.Sprintf("Output format %v", queue.ExportFormats))
+	queueExportCmd.Flags().StringVar(&queueExportStatus, "status", "", "Only export items with this status")
+	queueExportCmd.Flags().StringSliceVarP(&queueTags, "tag", "t", nil, "Only export items with this tag (repeatable)")
+	queueExportCmd.Flags().StringVarP(&queueExportOutput, "output", "o", "", "Write to a file instead of stdout")
+
+	queueCmd.AddCommand(queueImportCmd)
+	queueCmd.AddCommand(queueExportCmd)
+}
+
+// importQueue adds the items of every source to the queue.
+func importQueue(sources []string) error {
+	projectRoot, err := queueRoot()
+	if err != nil {
+		return err
+	}
+
+	var drafts []queue.Draft
+	for _, source := range sources {
+		parsed, err := readDrafts(source)
+		if err != nil {
+			return err
+		}
+		drafts = append(drafts, parsed...)
+	}
+	for i := range drafts {
+		drafts[i].Tags = append(drafts[i].Tags, queueTags...)
+	}
+
+	result, err := queue.Import(projectRoot, drafts)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range result.Added {
+		fmt.Printf("Imported #%d: %s\n", item.ID, item.Title)
+	}
+	if n := len(result.Duplicates); n > 0 {
+		fmt.Printf("Skipped %d duplicate(s)\n", n)
+	}
+	if len(result.Added) == 0 && len(result.Duplicates) == 0 {
+		fmt.Println("No items found")
+	}
+	return nil
+}
+
+// readDrafts parses a file, directory or stdin ("-") in the import format.
+func readDrafts(source string) ([]queue.Draft, error) {
+	var format queue.Format
+	if queueImportFormat != "" {
+		f, err := queue.ParseFormat(queueImportFormat)
+		if err != nil {
+			return nil, err
+		}
+		format = f
+	}
+
+	if source == "-" {
+		content, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stdin: %w", err)
+		}
+		if format == "" {
+			format = queue.SniffFormat(content)
+		}
+		drafts, err := queue.Parse(content, format)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse stdin: %w", err)
+		}
+		return drafts, nil
+	}
+
+	if format == "" {
+		f, err := queue.DetectFormat(source)
+		if err != nil {
+			return nil, err
+		}
+		format = f
+	}
+	drafts, err := queue.ParseFile(source, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", source, err)
+	}
+	return drafts, nil
+}
+
+// exportQueue writes the items matching the export flags.
+func exportQueue() error {
+	projectRoot, err := queueRoot()
+	if err != nil {
+		return err
+	}
+
+	filter := queue.Filter{Tags: queueTags}
+	if queueExportStatus != "" {
+		status, err := queue.ParseStatus(queueExportStatus)
+		if err != nil {
+			return err
+		}
+		filter.Status = status
+	}
+
+	items, err := queue.List(projectRoot, filter)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := queue.Export(&buf, items, queue.Format(queueExportFormat)); err != nil {
+		return err
+	}
+
+	if queueExportOutput == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	if err := os.WriteFile(queueExportOutput, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", queueExportOutput, err)
+	}
+	fmt.Printf("Exported %d item(s) to %s\n", len(items), queueExportOutput)
+	return nil
+}
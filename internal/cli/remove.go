@@ -2,21 +2,34 @@ package cli
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"planq.dev/planq/internal/queue"
 	"planq.dev/planq/internal/stackit"
 	"planq.dev/planq/internal/state"
 	"planq.dev/planq/internal/tmux"
 	"planq.dev/planq/internal/workspace"
 )
 
-var removeAll bool
+var (
+	removeAll     bool
+	removeAbandon bool
+)
 
 var removeCmd = &cobra.Command{
 	Use:   "remove <name>",
 	Short: "Remove a workspace",
-	Long:  `Remove a workspace by killing its tmux session and removing the git worktree.`,
+	Long: `Remove a workspace by killing its tmux session and removing the git worktree.
+
+A queue item the workspace was started from is archived, or with --abandon
+put back in the queue. planq does not merge branches itself, so remove a
+workspace once its branch has landed to archive its item. If the worktree
+cannot be removed, the item stays claimed.
+
+Worktrees taken over with 'planq adopt' are kept: remove only unregisters
+them and deletes their workspace metadata and .planq/agent directory.`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if removeAll {
 			if len(args) > 0 {
@@ -39,6 +52,7 @@ var removeCmd = &cobra.Command{
 
 func init() {
 	removeCmd.Flags().BoolVarP(&removeAll, "all", "a", false, "Remove all workspaces")
+	removeCmd.Flags().BoolVar(&removeAbandon, "abandon", false, "Reopen the workspace's queue item instead of archiving it")
 }
 
 // removeAllWorkspaces removes all planq workspaces.
@@ -95,39 +109,132 @@ func removeWorkspace(name string) error {
 		}
 	}
 
-	// Check if this is a main workspace
-	globalState, err := state.Load()
-	if err != nil {
-		fmt.Printf("  Warning: Could not load global state: %v\n", err)
-	} else if repoPath, isMain := globalState.FindMainWorkspaceByName(name); isMain {
+	// Check if this is a main workspace, trusting workspace.json over global state
+	isMain := false
+	mainPath := ""
+	worktreePath := ""
+	repoPath := ""
+	queueRoot := ""
+	queueItem := 0
+	adopted := false
+	if ws, meta, err := findWorkspace(name); err == nil {
+		worktreePath = ws.WorktreePath
+		repoPath = meta.Repo
+		queueItem = meta.QueueItem
+		adopted = meta.Adopted
+		// Workspaces started before the queue root was recorded used the repository's
+		queueRoot = meta.QueueRoot
+		if queueRoot == "" {
+			queueRoot = meta.Repo
+		}
+		if meta.Main {
+			isMain = true
+			mainPath = ws.WorktreePath
+		}
+	}
+
+	if !isMain {
+		// Workspaces without metadata fall back to the global state entry
+		if globalState, err := state.Load(); err != nil {
+			fmt.Printf("  Warning: Could not load global state: %v\n", err)
+		} else if path, found := globalState.FindMainWorkspaceByName(name); found {
+			isMain = true
+			mainPath = path
+		}
+	}
+
+	if isMain {
 		// This is a main workspace - clean up .agent and remove state entry, but preserve worktree
-		ws := &workspace.Workspace{Name: name, WorktreePath: repoPath}
+		ws := &workspace.Workspace{Name: name, WorktreePath: mainPath}
 		if err := ws.CleanupAgentDir(); err != nil {
 			fmt.Printf("  Warning: Could not clean up .agent directory: %v\n", err)
 		}
 		fmt.Println("  Removing main workspace registration...")
-		globalState.RemoveMainWorkspace(repoPath)
-		if err := globalState.Save(); err != nil {
+		err := state.Update(func(globalState *state.GlobalState) error {
+			globalState.RemoveMainWorkspace(mainPath)
+			globalState.UnregisterWorkspace(mainPath)
+			return nil
+		})
+		if err != nil {
 			fmt.Printf("  Warning: Could not save global state: %v\n", err)
 		}
+		settleQueueItem(queueRoot, queueItem)
 		fmt.Printf("Workspace %q removed (main worktree preserved)\n", name)
 		return nil
 	}
 
+	if adopted {
+		// The worktree was not created by planq, so leave it to its owner
+		ws := &workspace.Workspace{Name: name, WorktreePath: worktreePath}
+		if err := ws.CleanupAgentDir(); err != nil {
+			fmt.Printf("  Warning: Could not clean up .agent directory: %v\n", err)
+		}
+		if err := os.Remove(ws.MetadataFile()); err != nil && !os.IsNotExist(err) {
+			fmt.Printf("  Warning: Could not remove workspace metadata: %v\n", err)
+		}
+		fmt.Println("  Removing workspace registration...")
+		err := state.Update(func(globalState *state.GlobalState) error {
+			globalState.UnregisterWorkspace(worktreePath)
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("  Warning: Could not save global state: %v\n", err)
+		}
+		settleQueueItem(queueRoot, queueItem)
+		fmt.Printf("Workspace %q removed (adopted worktree preserved)\n", name)
+		return nil
+	}
+
 	// Not a main workspace - remove worktree via stackit
 	fmt.Printf("  Removing worktree %q...\n", name)
 	st := stackit.NewClient()
+	if repoPath != "" {
+		// The workspace may belong to another repository
+		st.SetWorkingDirectory(repoPath)
+	}
 	if err := st.WorktreeRemove(name); err != nil {
 		// Try force remove
 		if err := st.WorktreeRemoveForce(name); err != nil {
-			fmt.Printf("  Warning: Could not remove worktree: %v\n", err)
-		} else {
-			fmt.Println("  Worktree removed (forced)")
+			// The work is still on disk, so its queue item stays claimed
+			return fmt.Errorf("failed to remove worktree %q: %w", name, err)
 		}
+		fmt.Println("  Worktree removed (forced)")
 	} else {
 		fmt.Println("  Worktree removed")
 	}
 
+	if worktreePath != "" {
+		err := state.Update(func(globalState *state.GlobalState) error {
+			globalState.UnregisterWorkspace(worktreePath)
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("  Warning: Could not update workspace registry: %v\n", err)
+		}
+	}
+	settleQueueItem(queueRoot, queueItem)
+
 	fmt.Printf("Workspace %q removed\n", name)
 	return nil
 }
+
+// settleQueueItem archives the queue item a removed workspace was started
+// from, or reopens it when the workspace was abandoned.
+func settleQueueItem(queueRoot string, id int) {
+	if id == 0 || queueRoot == "" {
+		return
+	}
+
+	if removeAbandon {
+		fmt.Printf("  Reopening queue item #%d...\n", id)
+		if _, err := queue.Reopen(queueRoot, id); err != nil {
+			fmt.Printf("  Warning: Could not reopen queue item: %v\n", err)
+		}
+		return
+	}
+
+	fmt.Printf("  Archiving queue item #%d...\n", id)
+	if _, err := queue.Archive(queueRoot, id); err != nil {
+		fmt.Printf("  Warning: Could not archive queue item: %v\n", err)
+	}
+}
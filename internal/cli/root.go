@@ -26,14 +26,22 @@ func Execute() {
 
 func init() {
 	rootCmd.AddCommand(createCmd)
+	rootCmd.AddCommand(adoptCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(openCmd)
 	rootCmd.AddCommand(removeCmd)
 	rootCmd.AddCommand(modeCmd)
+	rootCmd.AddCommand(planCmd)
+	rootCmd.AddCommand(scratchCmd)
+	rootCmd.AddCommand(logCmd)
+	rootCmd.AddCommand(decisionsCmd)
+	rootCmd.AddCommand(learningsCmd)
 	rootCmd.AddCommand(cleanCmd)
+	rootCmd.AddCommand(doctorCmd)
 	rootCmd.AddCommand(helpCmd)
 	rootCmd.AddCommand(notifyCmd)
 	rootCmd.AddCommand(queueCmd)
+	rootCmd.AddCommand(dispatchCmd)
 	rootCmd.AddCommand(mcpCmd)
 	rootCmd.AddCommand(testCmd)
 }
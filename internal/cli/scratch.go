@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"planq.dev/planq/internal/workspace"
+)
+
+var scratchSection string
+
+var scratchCmd = &cobra.Command{
+	Use:   "scratch",
+	Short: "Show or edit the agent's scratch pad",
+	Long: `Show, edit or add to the scratch pad in .planq/agent/scratch.md.
+
+The scratch pad holds the agent's working notes for the workspace. Appended
+sections are timestamped and capped at 8 KB; the whole pad at 64 KB.
+
+Without a subcommand, shows the scratch pad.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showScratch()
+	},
+}
+
+var scratchShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the scratch pad",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showScratch()
+	},
+}
+
+var scratchEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit the scratch pad in $EDITOR",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return editScratch()
+	},
+}
+
+var scratchAppendCmd = &cobra.Command{
+	Use:   "append [text...]",
+	Short: "Add a timestamped section to the scratch pad",
+	Long: `Add a timestamped section to the scratch pad.
+
+The text is read from stdin when no arguments (or -) are given.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return appendScratch(args)
+	},
+}
+
+func init() {
+	scratchCmd.PersistentFlags().StringVarP(&modeWorkspace, "workspace", "w", "", "Workspace name (default: detect from environment)")
+	scratchCmd.PersistentFlags().StringVar(&modeWorktree, "worktree", "", "Worktree path (default: look the workspace up in the registry)")
+	scratchAppendCmd.Flags().StringVarP(&scratchSection, "section", "s", "", "Section header (default: the timestamp only)")
+
+	scratchCmd.AddCommand(scratchShowCmd)
+	scratchCmd.AddCommand(scratchEditCmd)
+	scratchCmd.AddCommand(scratchAppendCmd)
+}
+
+// scratchWorkspace loads the current workspace.
+func scratchWorkspace() (*workspace.Workspace, error) {
+	name, err := getWorkspaceName()
+	if err != nil {
+		return nil, err
+	}
+	ws, _, err := findWorkspace(name)
+	return ws, err
+}
+
+// showScratch prints the scratch pad.
+func showScratch() error {
+	ws, err := scratchWorkspace()
+	if err != nil {
+		return err
+	}
+
+	content, err := ws.ReadScratch()
+	if err != nil {
+		return err
+	}
+	if content == "" {
+		fmt.Printf("Scratch pad of workspace %q is empty\n", ws.Name)
+		return nil
+	}
+	fmt.Print(content)
+	return nil
+}
+
+// editScratch opens the scratch pad in the user's editor.
+func editScratch() error {
+	ws, err := scratchWorkspace()
+	if err != nil {
+		return err
+	}
+
+	// Make sure there is a file to edit
+	if _, err := os.Stat(ws.ScratchFile()); os.IsNotExist(err) {
+		if err := ws.ReplaceScratch(""); err != nil {
+			return err
+		}
+	}
+
+	if err := runEditor(ws.ScratchFile()); err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(ws.ScratchFile()); err == nil && info.Size() > workspace.MaxScratchSize {
+		fmt.Printf("Warning: the scratch pad is %d bytes, over the %d byte limit; agents cannot add to it until it is shortened\n", info.Size(), workspace.MaxScratchSize)
+	}
+	return nil
+}
+
+// appendScratch adds the text in args, or stdin, to the scratch pad.
+func appendScratch(args []string) error {
+	ws, err := scratchWorkspace()
+	if err != nil {
+		return err
+	}
+
+	text := strings.Join(args, " ")
+	if len(args) == 0 || text == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read stdin: %w", err)
+		}
+		text = string(data)
+	}
+
+	section, err := ws.AppendScratch(text, scratchSection)
+	if err != nil {
+		return err
+	}
+	header, _, _ := strings.Cut(section, "\n")
+	fmt.Printf("Added %q to %s\n", strings.TrimPrefix(header, "## "), ws.ScratchFile())
+	return nil
+}
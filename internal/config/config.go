@@ -0,0 +1,273 @@
+// Package config loads per-repository and user-level planq configuration.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"planq.dev/planq/internal/state"
+	"planq.dev/planq/internal/workspace"
+)
+
+const (
+	// FileName is the name of the config file.
+	FileName = "config.yaml"
+	// repoConfigDir is the directory within a repository that holds the config file.
+	repoConfigDir = ".planq"
+	// templatesDir is the directory holding plan templates, in the repo and user config dirs.
+	templatesDir = "templates"
+	// maxPanes is the largest number of panes a layout can describe.
+	maxPanes = 3
+)
+
+// Config holds planq settings for a repository.
+type Config struct {
+	Create  CreateConfig            `yaml:"create"`
+	Agent   AgentConfig             `yaml:"agent"`
+	Layouts map[string]LayoutConfig `yaml:"layouts"`
+}
+
+// CreateConfig holds defaults for planq create.
+type CreateConfig struct {
+	Scope string `yaml:"scope"`
+	// Template is the default plan template for new workspaces.
+	Template string `yaml:"template"`
+}
+
+// AgentConfig configures the agent launched in the agent pane.
+type AgentConfig struct {
+	// Name selects the agent backend (claude, aider, codex, gemini).
+	Name string `yaml:"name"`
+	// Command overrides the executable for the agent named by Name.
+	Command string `yaml:"command"`
+	// Modes selects a different agent backend for specific modes, unless the
+	// workspace was created with --agent.
+	Modes map[string]string `yaml:"modes"`
+}
+
+// LayoutConfig overrides the tmux layout for a mode.
+type LayoutConfig struct {
+	Panes []PaneConfig `yaml:"panes"`
+}
+
+// PaneConfig defines a single pane in a layout override.
+// Command may reference {plan_file}, {workspace} and {worktree}.
+type PaneConfig struct {
+	Name    string `yaml:"name"`
+	Title   string `yaml:"title"`
+	Size    int    `yaml:"size"`
+	Command string `yaml:"command"`
+}
+
+// Default returns the built-in configuration.
+func Default() *Config {
+	return &Config{
+		Agent: AgentConfig{
+			Name: workspace.DefaultAgentName,
+		},
+		Layouts: make(map[string]LayoutConfig),
+	}
+}
+
+// RepoFile returns the path to the config file for a repository.
+func RepoFile(repoRoot string) string {
+	return filepath.Join(repoRoot, repoConfigDir, FileName)
+}
+
+// UserFile returns the path to the user-level config file.
+func UserFile() (string, error) {
+	dir, err := state.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// TemplateDirs returns the plan template directories, repository first.
+func TemplateDirs(repoRoot string) ([]string, error) {
+	userFile, err := UserFile()
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		filepath.Join(repoRoot, repoConfigDir, templatesDir),
+		filepath.Join(filepath.Dir(userFile), templatesDir),
+	}, nil
+}
+
+// Load reads the built-in defaults, then the user-level config, then the
+// repository config, with later files overriding earlier ones.
+func Load(repoRoot string) (*Config, error) {
+	cfg := Default()
+
+	userFile, err := UserFile()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range []string{userFile, RepoFile(repoRoot)} {
+		if err := cfg.merge(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// merge decodes the file at path on top of the current config.
+// Missing files are ignored.
+func (c *Config) merge(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if c.Layouts == nil {
+		c.Layouts = make(map[string]LayoutConfig)
+	}
+	return nil
+}
+
+// Validate checks the configuration for errors.
+func (c *Config) Validate() error {
+	var problems []string
+
+	if _, err := workspace.LookupAgent(c.Agent.Name); err != nil {
+		problems = append(problems, fmt.Sprintf("agent.name: %v", err))
+	}
+	for mode, name := range c.Agent.Modes {
+		if !isModeName(mode) {
+			problems = append(problems, fmt.Sprintf("agent.modes.%s: unknown mode (expected one of %s)", mode, strings.Join(workspace.ModeNames(), ", ")))
+		} else if _, err := workspace.LookupAgent(name); err != nil {
+			problems = append(problems, fmt.Sprintf("agent.modes.%s: %v", mode, err))
+		}
+	}
+
+	for name, layout := range c.Layouts {
+		if !isModeName(name) {
+			problems = append(problems, fmt.Sprintf("layouts.%s: unknown mode (expected one of %s)", name, strings.Join(workspace.ModeNames(), ", ")))
+			continue
+		}
+		for _, p := range layout.validate() {
+			problems = append(problems, fmt.Sprintf("layouts.%s: %s", name, p))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid config:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// AgentFor returns the agent name and executable override to use in a mode.
+// The workspace agent, chosen with --agent, wins over a per-mode agent, which
+// wins over agent.name. agent.command only applies when the chosen agent is
+// the one named by agent.name.
+func (c *Config) AgentFor(mode, workspaceAgent string) (string, string) {
+	name := c.Agent.Name
+	if modeAgent, ok := c.Agent.Modes[mode]; ok {
+		name = modeAgent
+	}
+	if workspaceAgent != "" {
+		name = workspaceAgent
+	}
+
+	if name == c.Agent.Name {
+		return name, c.Agent.Command
+	}
+	return name, ""
+}
+
+// AgentChoice is an agent backend and the executable override it runs with.
+type AgentChoice struct {
+	Name   string
+	Binary string
+}
+
+// Agents returns every agent a workspace may run across its modes.
+func (c *Config) Agents(workspaceAgent string) []AgentChoice {
+	seen := make(map[AgentChoice]bool)
+	var choices []AgentChoice
+	for _, mode := range workspace.ModeNames() {
+		name, binary := c.AgentFor(mode, workspaceAgent)
+		choice := AgentChoice{Name: name, Binary: binary}
+		if !seen[choice] {
+			seen[choice] = true
+			choices = append(choices, choice)
+		}
+	}
+	return choices
+}
+
+// isModeName reports whether name is a registered mode.
+func isModeName(name string) bool {
+	_, err := workspace.LookupMode(workspace.Mode(name))
+	return err == nil
+}
+
+// validate returns the problems found in a layout override.
+func (l LayoutConfig) validate() []string {
+	var problems []string
+
+	if len(l.Panes) == 0 || len(l.Panes) > maxPanes {
+		problems = append(problems, fmt.Sprintf("must define between 1 and %d panes", maxPanes))
+	}
+
+	seen := make(map[string]bool)
+	total := 0
+	for i, pane := range l.Panes {
+		switch {
+		case pane.Name == "":
+			problems = append(problems, fmt.Sprintf("panes[%d]: name is required", i))
+		case seen[pane.Name]:
+			problems = append(problems, fmt.Sprintf("panes[%d]: duplicate pane name %q", i, pane.Name))
+		}
+		seen[pane.Name] = true
+
+		if pane.Size < 0 || pane.Size > 100 {
+			problems = append(problems, fmt.Sprintf("panes[%d]: size must be between 0 and 100", i))
+		}
+		total += pane.Size
+	}
+
+	if total > 100 {
+		problems = append(problems, fmt.Sprintf("pane sizes add up to %d%%, must not exceed 100%%", total))
+	}
+
+	if len(l.Panes) > 0 {
+		first := l.Panes[0]
+		if first.Name != "agent" {
+			problems = append(problems, "panes[0]: first pane must be named \"agent\"")
+		} else if first.Command != "" {
+			problems = append(problems, "panes[0]: agent pane command is set by agent.command")
+		}
+	}
+
+	return problems
+}
+
+// ExpandCommand replaces placeholders in a pane command.
+func ExpandCommand(command, planFile, workspaceName, worktreePath string) string {
+	return strings.NewReplacer(
+		"{plan_file}", planFile,
+		"{workspace}", workspaceName,
+		"{worktree}", worktreePath,
+	).Replace(command)
+}
@@ -0,0 +1,227 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeFile writes content to path, creating parent directories.
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	t.Setenv("PLANQ_HOME", t.TempDir())
+
+	cfg, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Agent.Name != "claude" {
+		t.Errorf("Agent.Name = %q, want %q", cfg.Agent.Name, "claude")
+	}
+	if len(cfg.Layouts) != 0 {
+		t.Errorf("Layouts = %v, want empty", cfg.Layouts)
+	}
+}
+
+func TestLoad_RepoOverridesUser(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("PLANQ_HOME", home)
+	repo := t.TempDir()
+
+	userFile, err := UserFile()
+	if err != nil {
+		t.Fatalf("UserFile() failed: %v", err)
+	}
+	writeFile(t, userFile, `
+create:
+  scope: team
+agent:
+  command: my-claude
+layouts:
+  execute:
+    panes:
+      - name: agent
+        size: 70
+      - name: diff
+        size: 30
+        command: git diff
+`)
+	writeFile(t, RepoFile(repo), `
+agent:
+  command: repo-claude
+layouts:
+  plan:
+    panes:
+      - name: agent
+        size: 50
+      - name: plan
+        size: 50
+        command: bat {plan_file}
+`)
+
+	cfg, err := Load(repo)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Agent.Command != "repo-claude" {
+		t.Errorf("Agent.Command = %q, want %q", cfg.Agent.Command, "repo-claude")
+	}
+	if cfg.Create.Scope != "team" {
+		t.Errorf("Create.Scope = %q, want %q", cfg.Create.Scope, "team")
+	}
+	if _, ok := cfg.Layouts["execute"]; !ok {
+		t.Error("user execute layout was not kept")
+	}
+	if got := cfg.Layouts["plan"].Panes[1].Command; got != "bat {plan_file}" {
+		t.Errorf("plan pane command = %q, want %q", got, "bat {plan_file}")
+	}
+}
+
+func TestLoad_RejectsUnknownFields(t *testing.T) {
+	t.Setenv("PLANQ_HOME", t.TempDir())
+	repo := t.TempDir()
+	writeFile(t, RepoFile(repo), "agent:\n  comand: claude\n")
+
+	if _, err := Load(repo); err == nil {
+		t.Fatal("Load() succeeded with a misspelled field, want error")
+	}
+}
+
+func TestLoad_RejectsUnknownAgent(t *testing.T) {
+	t.Setenv("PLANQ_HOME", t.TempDir())
+	repo := t.TempDir()
+	writeFile(t, RepoFile(repo), "agent:\n  modes:\n    execute: nope\n")
+
+	if _, err := Load(repo); err == nil {
+		t.Fatal("Load() succeeded with an unknown agent, want error")
+	}
+}
+
+func TestAgentFor(t *testing.T) {
+	cfg := Default()
+	cfg.Agent.Command = "/opt/claude"
+	cfg.Agent.Modes = map[string]string{"execute": "codex"}
+
+	tests := []struct {
+		mode, workspaceAgent string
+		wantName, wantBinary string
+	}{
+		{"plan", "", "claude", "/opt/claude"},
+		{"plan", "aider", "aider", ""},
+		{"execute", "", "codex", ""},
+		// An agent chosen for the workspace beats the per-mode agent
+		{"execute", "aider", "aider", ""},
+		{"execute", "claude", "claude", "/opt/claude"},
+	}
+
+	for _, tt := range tests {
+		name, binary := cfg.AgentFor(tt.mode, tt.workspaceAgent)
+		if name != tt.wantName || binary != tt.wantBinary {
+			t.Errorf("AgentFor(%q, %q) = (%q, %q), want (%q, %q)",
+				tt.mode, tt.workspaceAgent, name, binary, tt.wantName, tt.wantBinary)
+		}
+	}
+}
+
+func TestAgents(t *testing.T) {
+	cfg := Default()
+	cfg.Agent.Command = "/opt/claude"
+	cfg.Agent.Modes = map[string]string{"execute": "codex"}
+
+	got := cfg.Agents("")
+	want := []AgentChoice{{Name: "claude", Binary: "/opt/claude"}, {Name: "codex"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("Agents(\"\") = %+v, want %+v", got, want)
+	}
+	if got := cfg.Agents("aider"); !slices.Equal(got, []AgentChoice{{Name: "aider"}}) {
+		t.Errorf("Agents(\"aider\") = %+v, want only aider", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		layout  LayoutConfig
+		mode    string
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			mode:   "plan",
+			layout: LayoutConfig{Panes: []PaneConfig{{Name: "agent", Size: 60}, {Name: "plan", Size: 40}}},
+		},
+		{
+			name:    "unknown mode",
+			mode:    "nope",
+			layout:  LayoutConfig{Panes: []PaneConfig{{Name: "agent"}}},
+			wantErr: "unknown mode",
+		},
+		{
+			name:    "too many panes",
+			mode:    "plan",
+			layout:  LayoutConfig{Panes: []PaneConfig{{Name: "agent"}, {Name: "a"}, {Name: "b"}, {Name: "c"}}},
+			wantErr: "between 1 and 3 panes",
+		},
+		{
+			name:    "first pane not agent",
+			mode:    "plan",
+			layout:  LayoutConfig{Panes: []PaneConfig{{Name: "plan"}}},
+			wantErr: "must be named \"agent\"",
+		},
+		{
+			name:    "agent command set",
+			mode:    "plan",
+			layout:  LayoutConfig{Panes: []PaneConfig{{Name: "agent", Command: "vim"}}},
+			wantErr: "agent.command",
+		},
+		{
+			name:    "duplicate names",
+			mode:    "plan",
+			layout:  LayoutConfig{Panes: []PaneConfig{{Name: "agent"}, {Name: "x"}, {Name: "x"}}},
+			wantErr: "duplicate",
+		},
+		{
+			name:    "sizes over 100",
+			mode:    "execute",
+			layout:  LayoutConfig{Panes: []PaneConfig{{Name: "agent", Size: 80}, {Name: "diff", Size: 40}}},
+			wantErr: "must not exceed 100%",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Layouts[tt.mode] = tt.layout
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExpandCommand(t *testing.T) {
+	got := ExpandCommand("glow {plan_file} --tui # {workspace} in {worktree}", "/w/.planq/x.md", "x", "/w")
+	want := "glow /w/.planq/x.md --tui # x in /w"
+	if got != want {
+		t.Errorf("ExpandCommand() = %q, want %q", got, want)
+	}
+}
@@ -16,6 +16,7 @@ type Dependency struct {
 }
 
 // DefaultDependencies returns the list of dependencies to check.
+// Agent CLIs are not included; each agent backend declares its own.
 func DefaultDependencies() []Dependency {
 	return []Dependency{
 		{
@@ -30,12 +31,6 @@ func DefaultDependencies() []Dependency {
 			Description: "git worktree management",
 			InstallHint: "see https://github.com/getstackit/stackit",
 		},
-		{
-			Name:        "claude",
-			Required:    true,
-			Description: "Claude AI assistant CLI",
-			InstallHint: "npm install -g @anthropic-ai/claude-code",
-		},
 		{
 			Name:        "glow",
 			Required:    false,
@@ -85,9 +80,9 @@ func Check(dep Dependency) CheckResult {
 	return result
 }
 
-// CheckAll checks all default dependencies and returns results.
-func CheckAll() []CheckResult {
-	deps := DefaultDependencies()
+// CheckAll checks all default dependencies plus any extra ones and returns results.
+func CheckAll(extra ...Dependency) []CheckResult {
+	deps := append(DefaultDependencies(), extra...)
 	results := make([]CheckResult, len(deps))
 
 	for i, dep := range deps {
@@ -105,9 +100,9 @@ type ValidationResult struct {
 	AllRequiredMet  bool
 }
 
-// Validate checks all dependencies and returns a validation result.
-func Validate() ValidationResult {
-	results := CheckAll()
+// Validate checks all default dependencies plus any extra ones and returns a validation result.
+func Validate(extra ...Dependency) ValidationResult {
+	results := CheckAll(extra...)
 	validation := ValidationResult{
 		Results:        results,
 		AllRequiredMet: true,
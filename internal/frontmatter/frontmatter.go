@@ -0,0 +1,75 @@
+// Package frontmatter reads and writes markdown files with a YAML header.
+package frontmatter
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// delimiter opens and closes the YAML header.
+const delimiter = "---"
+
+// Split separates the YAML header from the markdown body.
+// ok is false when content has no header, in which case body is all of content.
+func Split(content []byte) (header, body []byte, ok bool) {
+	content = bytes.TrimPrefix(content, []byte("\ufeff"))
+	first, rest, found := bytes.Cut(content, []byte("\n"))
+	if !found || string(bytes.TrimRight(first, " \r")) != delimiter {
+		return nil, content, false
+	}
+
+	// Find the closing delimiter on a line of its own
+	offset := 0
+	for offset <= len(rest) {
+		line, next, more := bytes.Cut(rest[offset:], []byte("\n"))
+		if string(bytes.TrimRight(line, " \r")) == delimiter {
+			header = rest[:offset]
+			body = next
+			if !more {
+				body = nil
+			}
+			return header, body, true
+		}
+		if !more {
+			break
+		}
+		offset += len(line) + 1
+	}
+	return nil, content, false
+}
+
+// Parse decodes the YAML header of content into v and returns the body.
+// Content without a header leaves v untouched and is returned as the body.
+func Parse(content []byte, v any) (string, error) {
+	header, body, ok := Split(content)
+	if !ok {
+		return string(content), nil
+	}
+	if err := yaml.Unmarshal(header, v); err != nil {
+		return "", fmt.Errorf("failed to parse frontmatter: %w", err)
+	}
+	return string(bytes.TrimLeft(body, "\n")), nil
+}
+
+// Format encodes v as a YAML header followed by body.
+func Format(v any, body string) ([]byte, error) {
+	header, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal frontmatter: %w", err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(delimiter + "\n")
+	buf.Write(header)
+	buf.WriteString(delimiter + "\n")
+	if body != "" {
+		buf.WriteString("\n")
+		buf.WriteString(body)
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteString("\n")
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,70 @@
+package frontmatter
+
+import (
+	"testing"
+)
+
+type header struct {
+	ID    int      `yaml:"id"`
+	Title string   `yaml:"title"`
+	Tags  []string `yaml:"tags,omitempty"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := header{ID: 3, Title: "Fix login", Tags: []string{"bug"}}
+	content, err := Format(in, "Steps to reproduce\n\n---\n\nmore")
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	var out header
+	body, err := Parse(content, &out)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if out.ID != 3 || out.Title != "Fix login" || len(out.Tags) != 1 {
+		t.Errorf("header = %+v", out)
+	}
+	if body != "Steps to reproduce\n\n---\n\nmore\n" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestParseWithoutHeader(t *testing.T) {
+	tests := []string{
+		"just some text\n",
+		"---\nno closing delimiter\n",
+		"",
+	}
+	for _, content := range tests {
+		var out header
+		body, err := Parse([]byte(content), &out)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", content, err)
+		}
+		if body != content {
+			t.Errorf("Parse(%q) body = %q", content, body)
+		}
+		if out.ID != 0 {
+			t.Errorf("Parse(%q) decoded a header", content)
+		}
+	}
+}
+
+func TestParseEmptyBody(t *testing.T) {
+	var out header
+	body, err := Parse([]byte("---\nid: 1\n---"), &out)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if out.ID != 1 || body != "" {
+		t.Errorf("Parse() = %+v, %q", out, body)
+	}
+}
+
+func TestParseInvalidYAML(t *testing.T) {
+	var out header
+	if _, err := Parse([]byte("---\nid: [\n---\nbody\n"), &out); err == nil {
+		t.Error("Parse() succeeded on invalid YAML")
+	}
+}
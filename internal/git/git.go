@@ -4,12 +4,20 @@ import (
 	"bytes"
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
 // GetRepoRoot returns the root directory of the git repository.
 func GetRepoRoot() (string, error) {
+	return GetRepoRootIn("")
+}
+
+// GetRepoRootIn returns the root directory of the worktree containing dir,
+// or of the current directory if dir is empty.
+func GetRepoRootIn(dir string) (string, error) {
 	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = dir
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -32,3 +40,53 @@ func GetCurrentBranch() (string, error) {
 	}
 	return strings.TrimSpace(stdout.String()), nil
 }
+
+// GetUserName returns the configured git user.name.
+func GetUserName() (string, error) {
+	cmd := exec.Command("git", "config", "user.name")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to get git user name: %w (stderr: %s)", err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// GetBranchIn returns the branch checked out in the given directory.
+func GetBranchIn(dir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to get branch in %s: %w (stderr: %s)", dir, err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// GetMainRepoRoot returns the root of the main worktree of the repository
+// containing dir, so linked worktrees resolve to the repository they belong to.
+func GetMainRepoRoot(dir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--path-format=absolute", "--git-common-dir")
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to get main repo root: %w (stderr: %s)", err, stderr.String())
+	}
+	return filepath.Dir(strings.TrimSpace(stdout.String())), nil
+}
+
+// IsInsideWorkTree reports whether dir is inside a git working tree.
+func IsInsideWorkTree(dir string) bool {
+	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	return err == nil && strings.TrimSpace(string(out)) == "true"
+}
@@ -0,0 +1,195 @@
+// Package knowledge records the decisions agents make and the things they
+// learn, one markdown file per record.
+package knowledge
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"planq.dev/planq/internal/frontmatter"
+)
+
+// Kind is the kind of a record.
+type Kind string
+
+const (
+	// KindDecision records a choice between alternatives and why it was made.
+	KindDecision Kind = "decision"
+	// KindLearning records an insight worth keeping for later work.
+	KindLearning Kind = "learning"
+)
+
+// fileTimeFormat is the timestamp prefix of record file names.
+const fileTimeFormat = "2006-01-02T15-04-05"
+
+// Record is a single decision or learning.
+type Record struct {
+	Kind      Kind      `yaml:"-" json:"kind"`
+	Time      time.Time `yaml:"time" json:"time"`
+	Workspace string    `yaml:"workspace,omitempty" json:"workspace,omitempty"`
+	// Summary is the decision made or the insight learned.
+	Summary string `yaml:"summary" json:"summary"`
+	// Alternatives are the options a decision was chosen over.
+	Alternatives []string `yaml:"alternatives,omitempty" json:"alternatives,omitempty"`
+	// Detail is the rationale of a decision or the context of a learning.
+	// It is stored as the markdown body.
+	Detail string `yaml:"-" json:"detail,omitempty"`
+	// Path is the file the record was read from or written to.
+	Path string `yaml:"-" json:"path,omitempty"`
+}
+
+// Dir returns the directory records of kind are kept in under base, which is
+// a .planq or .planq/agent directory.
+func Dir(base string, kind Kind) string {
+	switch kind {
+	case KindLearning:
+		return filepath.Join(base, "learnings")
+	default:
+		return filepath.Join(base, "decisions")
+	}
+}
+
+// Save validates r and writes it to a new file in each of dirs. A zero Time
+// is set to now. The returned record has the Path of the first file.
+func Save(r Record, dirs ...string) (*Record, error) {
+	if r.Kind != KindDecision && r.Kind != KindLearning {
+		return nil, fmt.Errorf("invalid record kind %q", r.Kind)
+	}
+	r.Summary = strings.TrimSpace(r.Summary)
+	if r.Summary == "" {
+		return nil, fmt.Errorf("%s is empty", r.Kind)
+	}
+	r.Detail = strings.TrimSpace(r.Detail)
+	var alternatives []string
+	for _, alt := range r.Alternatives {
+		if alt = strings.TrimSpace(alt); alt != "" {
+			alternatives = append(alternatives, alt)
+		}
+	}
+	r.Alternatives = alternatives
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	r.Time = r.Time.UTC().Truncate(time.Second)
+
+	content, err := frontmatter.Format(&r, r.Detail)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Path = ""
+	for _, dir := range dirs {
+		path, err := create(dir, r.Time, content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save %s: %w", r.Kind, err)
+		}
+		if r.Path == "" {
+			r.Path = path
+		}
+	}
+	return &r, nil
+}
+
+// create writes content to a new timestamped file in dir. Records saved in
+// the same second get a numeric suffix.
+func create(dir string, t time.Time, content []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	name := t.Format(fileTimeFormat)
+	for i := 2; ; i++ {
+		path := filepath.Join(dir, name+".md")
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if os.IsExist(err) {
+			name = fmt.Sprintf("%s-%d", t.Format(fileTimeFormat), i)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.Write(content); err != nil {
+			f.Close()
+			return "", err
+		}
+		return path, f.Close()
+	}
+}
+
+// List returns the records of kind in dir, oldest first.
+// A missing directory has no records.
+func List(dir string, kind Kind) ([]Record, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s directory: %w", kind, err)
+	}
+
+	var records []Record
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		r, err := Parse(content, kind)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		r.Path = path
+		records = append(records, *r)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+	return records, nil
+}
+
+// Parse decodes a record file.
+func Parse(content []byte, kind Kind) (*Record, error) {
+	r := &Record{Kind: kind}
+	body, err := frontmatter.Parse(content, r)
+	if err != nil {
+		return nil, err
+	}
+	r.Detail = strings.TrimSpace(body)
+	return r, nil
+}
+
+// Search returns the records that pattern matches in the summary, an
+// alternative, the detail or the workspace name. A nil pattern matches all.
+func Search(records []Record, pattern *regexp.Regexp) []Record {
+	if pattern == nil {
+		return records
+	}
+	var matched []Record
+	for _, r := range records {
+		if r.matches(pattern) {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+func (r *Record) matches(pattern *regexp.Regexp) bool {
+	if pattern.MatchString(r.Summary) || pattern.MatchString(r.Detail) || pattern.MatchString(r.Workspace) {
+		return true
+	}
+	for _, alt := range r.Alternatives {
+		if pattern.MatchString(alt) {
+			return true
+		}
+	}
+	return false
+}
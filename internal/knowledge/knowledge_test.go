@@ -0,0 +1,123 @@
+package knowledge
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestSaveAndList(t *testing.T) {
+	base := t.TempDir()
+	agentDir := Dir(filepath.Join(base, "agent"), KindDecision)
+	repoDir := Dir(filepath.Join(base, "repo"), KindDecision)
+
+	at := time.Date(2026, 1, 21, 10, 30, 0, 0, time.UTC)
+	saved, err := Save(Record{
+		Kind:         KindDecision,
+		Time:         at,
+		Workspace:    "feature-x",
+		Summary:      "  Use flock for the queue lock ",
+		Alternatives: []string{"a lock directory", " ", "sqlite"},
+		Detail:       "Works across processes.\n\nReleased on crash.",
+	}, agentDir, repoDir)
+	if err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	if saved.Path != filepath.Join(agentDir, "2026-01-21T10-30-00.md") {
+		t.Errorf("Path = %q", saved.Path)
+	}
+
+	for _, dir := range []string{agentDir, repoDir} {
+		records, err := List(dir, KindDecision)
+		if err != nil {
+			t.Fatalf("List(%s) failed: %v", dir, err)
+		}
+		if len(records) != 1 {
+			t.Fatalf("List(%s) returned %d records, want 1", dir, len(records))
+		}
+		r := records[0]
+		if r.Kind != KindDecision || r.Summary != "Use flock for the queue lock" || r.Workspace != "feature-x" {
+			t.Errorf("unexpected record %+v", r)
+		}
+		if !r.Time.Equal(at) {
+			t.Errorf("Time = %v, want %v", r.Time, at)
+		}
+		if len(r.Alternatives) != 2 || r.Alternatives[1] != "sqlite" {
+			t.Errorf("Alternatives = %q", r.Alternatives)
+		}
+		if r.Detail != "Works across processes.\n\nReleased on crash." {
+			t.Errorf("Detail = %q", r.Detail)
+		}
+	}
+}
+
+func TestSaveSameSecond(t *testing.T) {
+	dir := t.TempDir()
+	at := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if _, err := Save(Record{Kind: KindLearning, Time: at, Summary: "insight"}, dir); err != nil {
+			t.Fatalf("Save() failed: %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("got %d files, want 3", len(entries))
+	}
+}
+
+func TestSaveValidation(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Save(Record{Kind: KindDecision, Summary: "  "}, dir); err == nil {
+		t.Error("expected an error for an empty summary")
+	}
+	if _, err := Save(Record{Kind: "guess", Summary: "x"}, dir); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+}
+
+func TestListMissingDir(t *testing.T) {
+	records, err := List(filepath.Join(t.TempDir(), "missing"), KindLearning)
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("got %d records, want 0", len(records))
+	}
+}
+
+func TestSearch(t *testing.T) {
+	records := []Record{
+		{Summary: "Use flock", Workspace: "queue-lock"},
+		{Summary: "Keep YAML", Alternatives: []string{"TOML"}},
+		{Summary: "Cache results", Detail: "The API is rate limited"},
+	}
+
+	tests := []struct {
+		pattern string
+		want    int
+	}{
+		{"(?i)flock", 1},
+		{"toml", 0},
+		{"(?i)toml", 1},
+		{"rate limit", 1},
+		{"queue-", 1},
+		{".", 3},
+	}
+	for _, tt := range tests {
+		got := Search(records, regexp.MustCompile(tt.pattern))
+		if len(got) != tt.want {
+			t.Errorf("Search(%q) returned %d records, want %d", tt.pattern, len(got), tt.want)
+		}
+	}
+
+	if got := Search(records, nil); len(got) != 3 {
+		t.Errorf("Search(nil) returned %d records, want 3", len(got))
+	}
+}
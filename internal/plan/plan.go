@@ -0,0 +1,130 @@
+// Package plan parses markdown plan files into checklist steps.
+package plan
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"planq.dev/planq/internal/statefile"
+)
+
+// checkboxPattern matches markdown task list items such as "- [ ] step" or "1. [x] step".
+var checkboxPattern = regexp.MustCompile(`^(\s*(?:[-*+]|\d+[.)])\s+\[)([ xX])(\]\s+)(.*)$`)
+
+// Step is a single checklist item in a plan.
+type Step struct {
+	// Number is the 1-based position of the step in the plan.
+	Number int    `json:"number"`
+	Text   string `json:"text"`
+	Done   bool   `json:"done"`
+	// Line is the 1-based line number of the step in the plan file.
+	Line int `json:"line"`
+}
+
+// Progress summarizes how many steps are done.
+type Progress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// String formats the progress as "7/12 steps".
+func (p Progress) String() string {
+	return fmt.Sprintf("%d/%d steps", p.Done, p.Total)
+}
+
+// Parse returns the checklist steps in a plan, skipping fenced code blocks.
+func Parse(content []byte) []Step {
+	var steps []Step
+	inFence := false
+
+	for i, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		m := checkboxPattern.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		steps = append(steps, Step{
+			Number: len(steps) + 1,
+			Text:   strings.TrimSpace(m[4]),
+			Done:   m[2] != " ",
+			Line:   i + 1,
+		})
+	}
+
+	return steps
+}
+
+// Summarize computes the progress of a list of steps.
+func Summarize(steps []Step) Progress {
+	p := Progress{Total: len(steps)}
+	for _, s := range steps {
+		if s.Done {
+			p.Done++
+		}
+	}
+	return p
+}
+
+// SetStepDone returns content with the checkbox of the given step (1-based) set.
+// Only the checkbox is changed; the rest of the plan is preserved as is.
+func SetStepDone(content []byte, number int, done bool) ([]byte, error) {
+	steps := Parse(content)
+	if number < 1 || number > len(steps) {
+		return nil, fmt.Errorf("plan has no step %d (has %d steps)", number, len(steps))
+	}
+
+	mark := " "
+	if done {
+		mark = "x"
+	}
+
+	lines := strings.Split(string(content), "\n")
+	idx := steps[number-1].Line - 1
+	lines[idx] = checkboxPattern.ReplaceAllString(lines[idx], "${1}"+mark+"${3}${4}")
+
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// ParseFile parses the plan file at path. A missing file has no steps.
+func ParseFile(path string) ([]Step, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+	return Parse(content), nil
+}
+
+// CheckStep marks a step in the plan file at path as done or not done.
+// The file is updated under its lock so concurrent checks don't clobber each other.
+func CheckStep(path string, number int, done bool) (Step, error) {
+	var step Step
+	err := statefile.Update(path, 0644, func(content []byte) ([]byte, error) {
+		if content == nil {
+			return nil, fmt.Errorf("plan file %s does not exist", path)
+		}
+
+		updated, err := SetStepDone(content, number, done)
+		if err != nil {
+			return nil, err
+		}
+		step = Parse(updated)[number-1]
+		return updated, nil
+	})
+	if err != nil {
+		return Step{}, err
+	}
+
+	return step, nil
+}
@@ -0,0 +1,68 @@
+package plan
+
+import (
+	"testing"
+)
+
+const samplePlan = `# Plan
+
+- [x] Add the parser
+- [ ] Wire it into list
+  - [X] Nested step
+1. [ ] Numbered step
+
+` + "```" + `
+- [ ] not a step
+` + "```" + `
+
+- not a checkbox
+* [ ] Star bullet
+`
+
+func TestParse(t *testing.T) {
+	steps := Parse([]byte(samplePlan))
+
+	want := []Step{
+		{Number: 1, Text: "Add the parser", Done: true, Line: 3},
+		{Number: 2, Text: "Wire it into list", Done: false, Line: 4},
+		{Number: 3, Text: "Nested step", Done: true, Line: 5},
+		{Number: 4, Text: "Numbered step", Done: false, Line: 6},
+		{Number: 5, Text: "Star bullet", Done: false, Line: 13},
+	}
+	if len(steps) != len(want) {
+		t.Fatalf("Parse() returned %d steps, want %d: %+v", len(steps), len(want), steps)
+	}
+	for i := range want {
+		if steps[i] != want[i] {
+			t.Errorf("step %d = %+v, want %+v", i+1, steps[i], want[i])
+		}
+	}
+
+	if got := Summarize(steps).String(); got != "2/5 steps" {
+		t.Errorf("Summarize() = %q, want %q", got, "2/5 steps")
+	}
+}
+
+func TestSetStepDone(t *testing.T) {
+	updated, err := SetStepDone([]byte(samplePlan), 2, true)
+	if err != nil {
+		t.Fatalf("SetStepDone() failed: %v", err)
+	}
+	if got := Summarize(Parse(updated)); got.Done != 3 {
+		t.Errorf("done steps = %d, want 3", got.Done)
+	}
+
+	updated, err = SetStepDone(updated, 1, false)
+	if err != nil {
+		t.Fatalf("SetStepDone() failed: %v", err)
+	}
+	want := samplePlan
+	want = "# Plan\n\n- [ ] Add the parser\n- [x] Wire it into list" + want[len("# Plan\n\n- [x] Add the parser\n- [ ] Wire it into list"):]
+	if string(updated) != want {
+		t.Errorf("SetStepDone() changed more than the checkbox:\n%s", updated)
+	}
+
+	if _, err := SetStepDone([]byte(samplePlan), 6, true); err == nil {
+		t.Error("SetStepDone() accepted an out of range step")
+	}
+}
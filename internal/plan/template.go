@@ -0,0 +1,93 @@
+package plan
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.md
+var builtinTemplates embed.FS
+
+// TemplateData holds the variables available to plan templates.
+type TemplateData struct {
+	// Name is the workspace name.
+	Name string
+	// Branch is the branch checked out in the worktree.
+	Branch string
+	// Scope is the worktree scope (may be empty).
+	Scope string
+	// Date is the creation date (YYYY-MM-DD).
+	Date string
+}
+
+// LoadTemplate returns the source of the named template.
+// dirs are searched in order before the built-in templates.
+func LoadTemplate(name string, dirs ...string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid template name %q", name)
+	}
+
+	for _, dir := range dirs {
+		data, err := os.ReadFile(filepath.Join(dir, name+".md"))
+		if err == nil {
+			return string(data), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read template %s: %w", name, err)
+		}
+	}
+
+	data, err := builtinTemplates.ReadFile("templates/" + name + ".md")
+	if err != nil {
+		return "", fmt.Errorf("unknown plan template %q (available: %s)", name, strings.Join(TemplateNames(dirs...), ", "))
+	}
+	return string(data), nil
+}
+
+// TemplateNames returns the names of all templates in dirs and the built-in templates.
+func TemplateNames(dirs ...string) []string {
+	seen := make(map[string]bool)
+
+	entries, _ := fs.ReadDir(builtinTemplates, "templates")
+	for _, dir := range dirs {
+		dirEntries, err := os.ReadDir(dir)
+		if err == nil {
+			entries = append(entries, dirEntries...)
+		}
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".md")
+		if entry.IsDir() || !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// RenderTemplate executes a plan template with the given data.
+func RenderTemplate(source string, data TemplateData) ([]byte, error) {
+	tmpl, err := template.New("plan").Option("missingkey=error").Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse plan template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render plan template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
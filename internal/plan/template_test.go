@@ -0,0 +1,58 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinTemplates(t *testing.T) {
+	data := TemplateData{Name: "add-auth", Branch: "add-auth", Scope: "team", Date: "2026-01-02"}
+
+	for _, name := range []string{"bugfix", "feature", "refactor", "spike"} {
+		source, err := LoadTemplate(name)
+		if err != nil {
+			t.Fatalf("LoadTemplate(%q) failed: %v", name, err)
+		}
+		content, err := RenderTemplate(source, data)
+		if err != nil {
+			t.Fatalf("RenderTemplate(%q) failed: %v", name, err)
+		}
+		if !strings.HasPrefix(string(content), "# add-auth\n") || !strings.Contains(string(content), "Scope: team") {
+			t.Errorf("template %q rendered unexpected content:\n%s", name, content)
+		}
+		if len(Parse(content)) == 0 {
+			t.Errorf("template %q has no checklist steps", name)
+		}
+	}
+}
+
+func TestLoadTemplate_Override(t *testing.T) {
+	repoDir := t.TempDir()
+	userDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(userDir, "feature.md"), []byte("user"), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(userDir, "chore.md"), []byte("chore"), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "chore.md"), []byte("repo"), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+
+	if got, _ := LoadTemplate("feature", repoDir, userDir); got != "user" {
+		t.Errorf("LoadTemplate(feature) = %q, want user override", got)
+	}
+	if got, _ := LoadTemplate("chore", repoDir, userDir); got != "repo" {
+		t.Errorf("LoadTemplate(chore) = %q, want repo template", got)
+	}
+	if _, err := LoadTemplate("../etc/passwd", repoDir); err == nil {
+		t.Error("LoadTemplate() accepted a path")
+	}
+
+	names := strings.Join(TemplateNames(repoDir, userDir), ",")
+	if names != "bugfix,chore,feature,refactor,spike" {
+		t.Errorf("TemplateNames() = %s", names)
+	}
+}
@@ -0,0 +1,175 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"planq.dev/planq/internal/statefile"
+)
+
+// DefaultLeaseDuration is how long an agent's claim lasts unless renewed.
+const DefaultLeaseDuration = 30 * time.Minute
+
+// leaseSuffix names the lock file holding an item's lease.
+const leaseSuffix = ".lock"
+
+// Lease records which workspace holds a claimed item and until when.
+// It is stored in a lock file next to the item while the item is claimed.
+type Lease struct {
+	Workspace string    `json:"workspace"`
+	ClaimedAt time.Time `json:"claimed_at"`
+	// ExpiresAt is zero for claims held until released, such as the claim
+	// of a workspace started from the item.
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+}
+
+// Expired reports whether the lease has run out at t.
+func (l *Lease) Expired(t time.Time) bool {
+	return !l.ExpiresAt.IsZero() && !t.Before(l.ExpiresAt)
+}
+
+// leasePath returns the lock file of an item's lease.
+func leasePath(projectRoot string, id int) string {
+	return filepath.Join(Dir(projectRoot), strconv.Itoa(id)+leaseSuffix)
+}
+
+// readLease returns an item's lease, or nil if it has none.
+func readLease(projectRoot string, id int) (*Lease, error) {
+	data, err := os.ReadFile(leasePath(projectRoot, id))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lease: %w", err)
+	}
+
+	var lease Lease
+	if err := json.Unmarshal(data, &lease); err != nil {
+		return nil, fmt.Errorf("failed to parse lease of queue item #%d: %w", id, err)
+	}
+	return &lease, nil
+}
+
+// writeLease saves an item's lease.
+func writeLease(projectRoot string, id int, lease *Lease) error {
+	data, err := json.MarshalIndent(lease, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal lease: %w", err)
+	}
+	if err := statefile.WriteFile(leasePath(projectRoot, id), data, 0644); err != nil {
+		return fmt.Errorf("failed to write lease: %w", err)
+	}
+	return nil
+}
+
+// removeLease deletes an item's lease, if any.
+func removeLease(projectRoot string, id int) error {
+	if err := os.Remove(leasePath(projectRoot, id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove lease: %w", err)
+	}
+	return nil
+}
+
+// Claim marks an item as being worked on in workspace. The claim lasts for
+// lease, or until released when lease is zero. Claiming an item the workspace
+// already holds renews the lease, but never limits a claim held until
+// released.
+func Claim(projectRoot string, id int, workspace string, lease time.Duration) (*Item, error) {
+	if workspace == "" {
+		return nil, fmt.Errorf("a workspace is required to claim a queue item")
+	}
+
+	return Update(projectRoot, id, func(item *Item) error {
+		switch {
+		case item.Status == StatusOpen:
+		case item.Status == StatusClaimed && item.Workspace == workspace:
+			if held := item.Lease; held != nil && held.Workspace == workspace {
+				switch {
+				case held.ExpiresAt.IsZero():
+				case lease > 0:
+					held.ExpiresAt = time.Now().Add(lease)
+				default:
+					held.ExpiresAt = time.Time{}
+				}
+				return nil
+			}
+		case item.Workspace != "":
+			return fmt.Errorf("queue item #%d is %s by workspace %q", item.ID, item.Status, item.Workspace)
+		default:
+			return fmt.Errorf("queue item #%d is %s", item.ID, item.Status)
+		}
+
+		now := time.Now()
+		item.Status = StatusClaimed
+		item.Workspace = workspace
+		item.Lease = &Lease{Workspace: workspace, ClaimedAt: now}
+		if lease > 0 {
+			item.Lease.ExpiresAt = now.Add(lease)
+		}
+		return nil
+	})
+}
+
+// Release gives up workspace's claim and puts the item back in the queue.
+func Release(projectRoot string, id int, workspace string) (*Item, error) {
+	return Update(projectRoot, id, func(item *Item) error {
+		if err := checkHolder(item, workspace); err != nil {
+			return err
+		}
+		item.Status = StatusOpen
+		item.Workspace = ""
+		return nil
+	})
+}
+
+// Complete marks an item claimed by workspace as done.
+// The workspace name is kept for reference.
+func Complete(projectRoot string, id int, workspace string) (*Item, error) {
+	return Update(projectRoot, id, func(item *Item) error {
+		if err := checkHolder(item, workspace); err != nil {
+			return err
+		}
+		item.Status = StatusDone
+		return nil
+	})
+}
+
+// checkHolder ensures that workspace holds the claim on item.
+func checkHolder(item *Item, workspace string) error {
+	if item.Status != StatusClaimed {
+		return fmt.Errorf("queue item #%d is %s, not claimed", item.ID, item.Status)
+	}
+	if item.Workspace != workspace {
+		return fmt.Errorf("queue item #%d is claimed by workspace %q, not %q", item.ID, item.Workspace, workspace)
+	}
+	return nil
+}
+
+// expireLeases puts items whose lease has run out back in the queue.
+func expireLeases(projectRoot string, items []*Item) error {
+	now := time.Now()
+	for _, item := range items {
+		if item.Status != StatusClaimed {
+			continue
+		}
+		lease, err := readLease(projectRoot, item.ID)
+		if err != nil {
+			return err
+		}
+		if lease == nil || !lease.Expired(now) {
+			item.Lease = lease
+			continue
+		}
+
+		item.Status = StatusOpen
+		item.Workspace = ""
+		if err := write(projectRoot, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package queue
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClaimReleaseComplete(t *testing.T) {
+	root := t.TempDir()
+	item, err := Add(root, "Speed up CI", AddOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claimed, err := Claim(root, item.ID, "ci", time.Hour)
+	if err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	if claimed.Lease == nil || claimed.Lease.Workspace != "ci" || claimed.Lease.ExpiresAt.IsZero() {
+		t.Errorf("claimed.Lease = %+v", claimed.Lease)
+	}
+	if _, err := os.Stat(leasePath(root, item.ID)); err != nil {
+		t.Errorf("lease file missing: %v", err)
+	}
+
+	// Claiming again from the same workspace renews the lease
+	renewed, err := Claim(root, item.ID, "ci", 2*time.Hour)
+	if err != nil {
+		t.Fatalf("Claim() renewal error = %v", err)
+	}
+	if !renewed.Lease.ExpiresAt.After(claimed.Lease.ExpiresAt) {
+		t.Errorf("renewed lease expires %v, want after %v", renewed.Lease.ExpiresAt, claimed.Lease.ExpiresAt)
+	}
+
+	if _, err := Release(root, item.ID, "other"); err == nil {
+		t.Error("Release() succeeded for a workspace without the claim")
+	}
+	released, err := Release(root, item.ID, "ci")
+	if err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if released.Status != StatusOpen || released.Workspace != "" || released.Lease != nil {
+		t.Errorf("released = %+v", released)
+	}
+	if _, err := os.Stat(leasePath(root, item.ID)); !os.IsNotExist(err) {
+		t.Errorf("lease file still present after release: %v", err)
+	}
+
+	if _, err := Complete(root, item.ID, "ci"); err == nil {
+		t.Error("Complete() succeeded on an open item")
+	}
+	if _, err := Claim(root, item.ID, "ci", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	done, err := Complete(root, item.ID, "ci")
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if done.Status != StatusDone || done.Workspace != "ci" {
+		t.Errorf("done = %+v", done)
+	}
+	if _, err := Claim(root, item.ID, "ci", time.Hour); err == nil {
+		t.Error("Claim() succeeded on a done item")
+	}
+}
+
+func TestRenewalKeepsClaimUntilReleased(t *testing.T) {
+	root := t.TempDir()
+	item, err := Add(root, "Started from the queue", AddOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	held, err := Claim(root, item.ID, "ws", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An agent in the workspace claiming the item again must not limit it
+	renewed, err := Claim(root, item.ID, "ws", time.Millisecond)
+	if err != nil {
+		t.Fatalf("Claim() renewal error = %v", err)
+	}
+	if !renewed.Lease.ExpiresAt.IsZero() {
+		t.Errorf("renewed lease expires %v, want never", renewed.Lease.ExpiresAt)
+	}
+	if !renewed.Lease.ClaimedAt.Equal(held.Lease.ClaimedAt) {
+		t.Errorf("renewed lease claimed at %v, want %v", renewed.Lease.ClaimedAt, held.Lease.ClaimedAt)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if _, err := Claim(root, item.ID, "other", time.Hour); err == nil {
+		t.Error("Claim() took over a claim held until released")
+	}
+}
+
+func TestExpiredLeaseIsReclaimed(t *testing.T) {
+	root := t.TempDir()
+	item, err := Add(root, "Flaky test", AddOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Claim(root, item.ID, "gone", time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	open, err := List(root, Filter{Status: StatusOpen})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(open) != 1 || open[0].Workspace != "" {
+		t.Fatalf("List(open) = %+v, want the expired item", open)
+	}
+
+	claimed, err := Claim(root, item.ID, "next", time.Hour)
+	if err != nil {
+		t.Fatalf("Claim() after expiry error = %v", err)
+	}
+	if claimed.Workspace != "next" {
+		t.Errorf("claimed.Workspace = %q", claimed.Workspace)
+	}
+}
+
+func TestConcurrentClaims(t *testing.T) {
+	root := t.TempDir()
+	item, err := Add(root, "Only one agent", AddOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const agents = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, agents)
+	for i := range agents {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := Claim(root, item.ID, string(rune('a'+i)), time.Hour)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	won := 0
+	for err := range errs {
+		if err == nil {
+			won++
+		}
+	}
+	if won != 1 {
+		t.Errorf("%d claims succeeded, want 1", won)
+	}
+}
@@ -5,69 +5,378 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+
+	"planq.dev/planq/internal/frontmatter"
+	"planq.dev/planq/internal/statefile"
+)
+
+// Status is the lifecycle state of a queue item.
+type Status string
+
+const (
+	// StatusOpen items are waiting to be worked on.
+	StatusOpen Status = "open"
+	// StatusClaimed items are being worked on in a workspace.
+	StatusClaimed Status = "claimed"
+	// StatusDone items are finished and hidden from the default listing.
+	StatusDone Status = "done"
+	// StatusArchived items belonged to a workspace that has been removed.
+	StatusArchived Status = "archived"
+)
+
+// Statuses lists every valid status.
+var Statuses = []Status{StatusOpen, StatusClaimed, StatusDone, StatusArchived}
+
+// ParseStatus validates a status name.
+func ParseStatus(s string) (Status, error) {
+	for _, status := range Statuses {
+		if string(status) == s {
+			return status, nil
+		}
+	}
+	return "", fmt.Errorf("unknown status %q (available: %v)", s, Statuses)
+}
+
+const (
+	// HighestPriority is the most urgent priority.
+	HighestPriority = 1
+	// LowestPriority is the least urgent priority.
+	LowestPriority = 5
+	// DefaultPriority is used when no priority is given.
+	DefaultPriority = 3
+
+	// timestampFormat names queue files after their creation time.
+	timestampFormat = "2006-01-02T15-04-05"
+	// maxTitleLength limits titles derived from the item text.
+	maxTitleLength = 72
 )
 
+// Item represents a queued work item. The fields are stored as YAML
+// frontmatter at the top of the item's markdown file.
+type Item struct {
+	ID        int       `yaml:"id" json:"id"`
+	Title     string    `yaml:"title" json:"title"`
+	Priority  int       `yaml:"priority" json:"priority"`
+	Tags      []string  `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Status    Status    `yaml:"status" json:"status"`
+	Source    string    `yaml:"source,omitempty" json:"source,omitempty"`
+	CreatedAt time.Time `yaml:"created_at" json:"created_at"`
+	// Workspace is the workspace that claimed the item.
+	Workspace string `yaml:"workspace,omitempty" json:"workspace,omitempty"`
+	// Lease is the claim on the item, stored in its own lock file.
+	Lease *Lease `yaml:"-" json:"lease,omitempty"`
+
+	// Body is the markdown below the frontmatter.
+	Body string `yaml:"-" json:"body"`
+	// Filename is the item's file name within the queue directory.
+	Filename string `yaml:"-" json:"filename"`
+}
+
+// HasTag reports whether the item is tagged with tag.
+func (i *Item) HasTag(tag string) bool {
+	for _, t := range i.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// AddOptions describe a new queue item.
+type AddOptions struct {
+	// Title defaults to the first line of the text.
+	Title string
+	// Priority defaults to DefaultPriority.
+	Priority int
+	Tags     []string
+	// Source is the workspace the item was queued from.
+	Source string
+}
+
+// Filter selects queue items. Zero fields match everything.
+type Filter struct {
+	Status Status
+	Tags   []string
+	Source string
+}
+
+// Match reports whether the item passes the filter.
+func (f Filter) Match(item *Item) bool {
+	if f.Status != "" && item.Status != f.Status {
+		return false
+	}
+	if f.Source != "" && item.Source != f.Source {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !item.HasTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
 // Dir returns the path to the queue directory for a project.
 func Dir(projectRoot string) string {
 	return filepath.Join(projectRoot, ".planq", "queue")
 }
 
-// Add saves a text item to the queue and returns the created file path.
-func Add(projectRoot, text string) (string, error) {
-	queueDir := Dir(projectRoot)
+// ValidatePriority checks that p is within the priority range.
+func ValidatePriority(p int) error {
+	if p < HighestPriority || p > LowestPriority {
+		return fmt.Errorf("priority must be between %d (highest) and %d (lowest), got %d", HighestPriority, LowestPriority, p)
+	}
+	return nil
+}
 
-	// Create the queue directory if it doesn't exist
-	if err := os.MkdirAll(queueDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create queue directory: %w", err)
+// ParseID parses an item ID as shown by planq queue list ("7" or "#7").
+func ParseID(s string) (int, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(s, "#"))
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid queue item id %q", s)
+	}
+	return id, nil
+}
+
+// Add saves a text item to the queue and returns it.
+func Add(projectRoot, text string, opts AddOptions) (*Item, error) {
+	var item *Item
+	err := withQueue(projectRoot, func(items []*Item) error {
+		var err error
+		item, err = add(projectRoot, items, text, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// List returns the queued items matching filter, most urgent first and
+// oldest first within a priority.
+func List(projectRoot string, filter Filter) ([]Item, error) {
+	var result []Item
+	err := withLock(projectRoot, func(items []*Item) error {
+		for _, item := range items {
+			if filter.Match(item) {
+				result = append(result, *item)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Priority != result[j].Priority {
+			return result[i].Priority < result[j].Priority
+		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+// Get returns the item with the given ID.
+func Get(projectRoot string, id int) (*Item, error) {
+	var found *Item
+	err := withLock(projectRoot, func(items []*Item) error {
+		item, err := find(items, id)
+		found = item
+		return err
+	})
+	return found, err
+}
+
+// Path returns the file path of a queue item.
+func Path(projectRoot string, item *Item) string {
+	return filepath.Join(Dir(projectRoot), item.Filename)
+}
+
+// Update applies fn to the item with the given ID and saves it.
+func Update(projectRoot string, id int, fn func(item *Item) error) (*Item, error) {
+	var updated *Item
+	err := withLock(projectRoot, func(items []*Item) error {
+		item, err := find(items, id)
+		if err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+		updated = item
+		return write(projectRoot, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// SetStatus changes the status of an item.
+func SetStatus(projectRoot string, id int, status Status) (*Item, error) {
+	return Update(projectRoot, id, func(item *Item) error {
+		item.Status = status
+		return nil
+	})
+}
+
+// SetPriority changes the priority of an item.
+func SetPriority(projectRoot string, id, priority int) (*Item, error) {
+	if err := ValidatePriority(priority); err != nil {
+		return nil, err
+	}
+	return Update(projectRoot, id, func(item *Item) error {
+		item.Priority = priority
+		return nil
+	})
+}
+
+// Reopen puts an item back in the queue, e.g. when its workspace was abandoned.
+func Reopen(projectRoot string, id int) (*Item, error) {
+	return Update(projectRoot, id, func(item *Item) error {
+		item.Status = StatusOpen
+		item.Workspace = ""
+		return nil
+	})
+}
+
+// Archive marks an item as finished by its workspace.
+// The workspace name is kept for reference.
+func Archive(projectRoot string, id int) (*Item, error) {
+	return SetStatus(projectRoot, id, StatusArchived)
+}
+
+// Remove deletes an item from the queue.
+func Remove(projectRoot string, id int) (*Item, error) {
+	var removed *Item
+	err := withLock(projectRoot, func(items []*Item) error {
+		item, err := find(items, id)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(Path(projectRoot, item)); err != nil {
+			return fmt.Errorf("failed to remove queue item: %w", err)
+		}
+		if err := removeLease(projectRoot, item.ID); err != nil {
+			return err
+		}
+		removed = item
+		return nil
+	})
+	return removed, err
+}
+
+// Validate parses an item file, e.g. after it was edited by hand.
+func Validate(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read queue item: %w", err)
+	}
+	item, err := parse(filepath.Base(path), content)
+	if err != nil {
+		return err
+	}
+	if _, err := ParseStatus(string(item.Status)); err != nil {
+		return err
+	}
+	return ValidatePriority(item.Priority)
+}
+
+// withQueue is withLock for changes that add items, creating the queue
+// directory first.
+func withQueue(projectRoot string, fn func(items []*Item) error) error {
+	if err := os.MkdirAll(Dir(projectRoot), 0755); err != nil {
+		return fmt.Errorf("failed to create queue directory: %w", err)
+	}
+	return withLock(projectRoot, fn)
+}
+
+// add writes a new item with the next free ID. The queue lock must be held.
+func add(projectRoot string, items []*Item, text string, opts AddOptions) (*Item, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("queue item text is empty")
+	}
+	if opts.Priority == 0 {
+		opts.Priority = DefaultPriority
+	}
+	if err := ValidatePriority(opts.Priority); err != nil {
+		return nil, err
 	}
 
 	// Generate timestamp-based filename
-	timestamp := time.Now().Format("2006-01-02T15-04-05")
+	now := time.Now()
+	timestamp := now.Format(timestampFormat)
 	filename := timestamp + ".md"
-	filePath := filepath.Join(queueDir, filename)
 
 	// Handle potential collision by adding suffix
-	for i := 1; fileExists(filePath); i++ {
+	for i := 1; fileExists(filepath.Join(Dir(projectRoot), filename)); i++ {
 		filename = fmt.Sprintf("%s-%d.md", timestamp, i)
-		filePath = filepath.Join(queueDir, filename)
 	}
 
-	// Write the content
-	content := strings.TrimSpace(text) + "\n"
-	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
-		return "", fmt.Errorf("failed to write queue item: %w", err)
+	title := opts.Title
+	if title == "" {
+		title = deriveTitle(text)
 	}
 
-	return filePath, nil
+	item := &Item{
+		ID:        nextID(projectRoot, items),
+		Title:     title,
+		Priority:  opts.Priority,
+		Tags:      opts.Tags,
+		Status:    StatusOpen,
+		Source:    opts.Source,
+		CreatedAt: now,
+		Body:      text,
+		Filename:  filename,
+	}
+	if err := write(projectRoot, item); err != nil {
+		return nil, err
+	}
+	return item, nil
 }
 
-// fileExists checks if a file exists.
-func fileExists(path string) bool {
-	_, err := os.Stat(path)
-	return err == nil
-}
+// withLock loads every queue item while holding the queue lock and calls fn.
+// Items written before frontmatter existed are upgraded in place, and items
+// whose lease has expired are put back in the queue.
+func withLock(projectRoot string, fn func(items []*Item) error) error {
+	queueDir := Dir(projectRoot)
+	if !fileExists(queueDir) {
+		return fn(nil) // No queue directory = no items
+	}
 
-// Item represents a queued work item.
-type Item struct {
-	Filename string
-	Content  string
+	lock, err := statefile.Acquire(queueDir)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	items, err := load(projectRoot)
+	if err != nil {
+		return err
+	}
+	if err := expireLeases(projectRoot, items); err != nil {
+		return err
+	}
+	return fn(items)
 }
 
-// List returns all queued items, sorted by filename (oldest first).
-func List(projectRoot string) ([]Item, error) {
+// load reads all queue items, sorted by filename (oldest first).
+func load(projectRoot string) ([]*Item, error) {
 	queueDir := Dir(projectRoot)
 
 	entries, err := os.ReadDir(queueDir)
-	if os.IsNotExist(err) {
-		return nil, nil // No queue directory = no items
-	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read queue directory: %w", err)
 	}
 
-	var items []Item
+	var items, legacy []*Item
 	for _, entry := range entries {
 		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
 			continue
@@ -78,11 +387,149 @@ func List(projectRoot string) ([]Item, error) {
 			continue // Skip unreadable files
 		}
 
-		items = append(items, Item{
-			Filename: entry.Name(),
-			Content:  strings.TrimSpace(string(content)),
-		})
+		item, err := parse(entry.Name(), content)
+		if err != nil {
+			continue // Skip files with broken frontmatter
+		}
+		if item.ID == 0 {
+			legacy = append(legacy, item)
+		}
+		items = append(items, item)
+	}
+
+	// Give items without frontmatter an ID and write it down
+	for _, item := range legacy {
+		item.ID = nextID(projectRoot, items)
+		if err := write(projectRoot, item); err != nil {
+			return nil, err
+		}
 	}
 
 	return items, nil
 }
+
+// parse decodes a queue file. Files without frontmatter become open items
+// titled after their first line.
+func parse(filename string, content []byte) (*Item, error) {
+	item := &Item{Filename: filename}
+	body, err := frontmatter.Parse(content, item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse queue item %s: %w", filename, err)
+	}
+	item.Body = strings.TrimSpace(body)
+
+	if item.Title == "" {
+		item.Title = deriveTitle(item.Body)
+	}
+	if item.Priority == 0 {
+		item.Priority = DefaultPriority
+	}
+	if item.Status == "" {
+		item.Status = StatusOpen
+	}
+	if item.CreatedAt.IsZero() {
+		stamp := strings.TrimSuffix(filename, ".md")
+		if i := len(timestampFormat); len(stamp) > i {
+			stamp = stamp[:i]
+		}
+		if t, err := time.ParseInLocation(timestampFormat, stamp, time.Local); err == nil {
+			item.CreatedAt = t
+		}
+	}
+	return item, nil
+}
+
+// write saves an item to its file. The lease lock file is kept only while
+// the item is claimed.
+func write(projectRoot string, item *Item) error {
+	if item.Status != StatusClaimed {
+		item.Lease = nil
+		if err := removeLease(projectRoot, item.ID); err != nil {
+			return err
+		}
+	} else if item.Lease != nil {
+		if err := writeLease(projectRoot, item.ID, item.Lease); err != nil {
+			return err
+		}
+	}
+
+	content, err := frontmatter.Format(item, item.Body)
+	if err != nil {
+		return err
+	}
+	if err := statefile.WriteFile(Path(projectRoot, item), content, 0644); err != nil {
+		return fmt.Errorf("failed to write queue item: %w", err)
+	}
+	return nil
+}
+
+// find returns the item with the given ID.
+func find(items []*Item, id int) (*Item, error) {
+	for _, item := range items {
+		if item.ID == id {
+			return item, nil
+		}
+	}
+	return nil, fmt.Errorf("queue item #%d not found", id)
+}
+
+// idLine matches the ID in the frontmatter of an item file.
+var idLine = regexp.MustCompile(`(?m)^id:\s*(\d+)\s*$`)
+
+// nextID returns an ID one higher than any in use. Besides the loaded items,
+// IDs are taken from the files in the queue directory, so that items that
+// failed to parse and leases left behind keep theirs.
+func nextID(projectRoot string, items []*Item) int {
+	highest := 0
+	use := func(id int) {
+		if id > highest {
+			highest = id
+		}
+	}
+
+	loaded := make(map[string]bool, len(items))
+	for _, item := range items {
+		use(item.ID)
+		loaded[item.Filename] = true
+	}
+
+	queueDir := Dir(projectRoot)
+	entries, _ := os.ReadDir(queueDir)
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case entry.IsDir():
+		case strings.HasSuffix(name, leaseSuffix):
+			if id, err := strconv.Atoi(strings.TrimSuffix(name, leaseSuffix)); err == nil {
+				use(id)
+			}
+		case strings.HasSuffix(name, ".md") && !loaded[name]:
+			content, err := os.ReadFile(filepath.Join(queueDir, name))
+			if err != nil {
+				continue
+			}
+			if m := idLine.FindSubmatch(content); m != nil {
+				if id, err := strconv.Atoi(string(m[1])); err == nil {
+					use(id)
+				}
+			}
+		}
+	}
+	return highest + 1
+}
+
+// deriveTitle uses the first line of text as the title.
+func deriveTitle(text string) string {
+	title, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	title = strings.TrimSpace(strings.TrimLeft(title, "# "))
+	if runes := []rune(title); len(runes) > maxTitleLength {
+		title = strings.TrimSpace(string(runes[:maxTitleLength-3])) + "..."
+	}
+	return title
+}
+
+// fileExists checks if a file exists.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
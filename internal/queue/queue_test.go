@@ -0,0 +1,257 @@
+package queue
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAddAndList(t *testing.T) {
+	root := t.TempDir()
+
+	first, err := Add(root, "Fix the login redirect\n\nIt loops forever.", AddOptions{Tags: []string{"bug"}, Source: "auth"})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if first.ID != 1 || first.Title != "Fix the login redirect" || first.Priority != DefaultPriority || first.Status != StatusOpen {
+		t.Errorf("first item = %+v", first)
+	}
+
+	second, err := Add(root, "Urgent thing", AddOptions{Priority: 1, Title: "Urgent"})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if second.ID != 2 {
+		t.Errorf("second.ID = %d, want 2", second.ID)
+	}
+
+	items, err := List(root, Filter{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(items) != 2 || items[0].ID != 2 || items[1].ID != 1 {
+		t.Fatalf("List() = %+v, want most urgent first", items)
+	}
+	if items[1].Body != "Fix the login redirect\n\nIt loops forever." {
+		t.Errorf("Body = %q", items[1].Body)
+	}
+
+	tagged, err := List(root, Filter{Tags: []string{"bug"}, Source: "auth"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tagged) != 1 || tagged[0].ID != 1 {
+		t.Errorf("List(tag=bug) = %+v", tagged)
+	}
+
+	if _, err := Add(root, "bad", AddOptions{Priority: 9}); err == nil {
+		t.Error("Add() accepted priority 9")
+	}
+	if _, err := Add(root, "  ", AddOptions{}); err == nil {
+		t.Error("Add() accepted empty text")
+	}
+}
+
+func TestListMissingQueue(t *testing.T) {
+	root := t.TempDir()
+
+	items, err := List(root, Filter{})
+	if err != nil || len(items) != 0 {
+		t.Errorf("List() = %v, %v; want no items", items, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".planq")); !os.IsNotExist(err) {
+		t.Error("List() should not create the .planq directory")
+	}
+}
+
+func TestUpdateStatusPriorityRemove(t *testing.T) {
+	root := t.TempDir()
+	item, err := Add(root, "Refactor config", AddOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := SetStatus(root, item.ID, StatusDone); err != nil {
+		t.Fatalf("SetStatus() error = %v", err)
+	}
+	open, err := List(root, Filter{Status: StatusOpen})
+	if err != nil || len(open) != 0 {
+		t.Errorf("List(open) = %v, %v; want none", open, err)
+	}
+
+	if _, err := SetPriority(root, item.ID, 0); err == nil {
+		t.Error("SetPriority(0) succeeded")
+	}
+	updated, err := SetPriority(root, item.ID, 5)
+	if err != nil {
+		t.Fatalf("SetPriority() error = %v", err)
+	}
+	if updated.Priority != 5 || updated.Status != StatusDone {
+		t.Errorf("updated = %+v", updated)
+	}
+
+	got, err := Get(root, item.ID)
+	if err != nil || got.Priority != 5 {
+		t.Errorf("Get() = %+v, %v", got, err)
+	}
+
+	if _, err := Remove(root, item.ID); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := Get(root, item.ID); err == nil {
+		t.Error("Get() found a removed item")
+	}
+}
+
+func TestUpdateKeepsHandEditedPriority(t *testing.T) {
+	root := t.TempDir()
+	item, err := Add(root, "Edited by hand", AddOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := Path(root, item)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := strings.Replace(string(content), "priority: 3", "priority: 9", 1)
+	if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changes that do not touch the priority still work
+	claimed, err := Claim(root, item.ID, "ws", 0)
+	if err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	if claimed.Priority != 9 {
+		t.Errorf("Priority = %d, want the edited 9", claimed.Priority)
+	}
+	if _, err := Reopen(root, item.ID); err != nil {
+		t.Fatalf("Reopen() error = %v", err)
+	}
+
+	if _, err := SetPriority(root, item.ID, 6); err == nil {
+		t.Error("SetPriority(6) succeeded")
+	}
+}
+
+func TestNextIDSkipsUnparseableItems(t *testing.T) {
+	root := t.TempDir()
+	if _, err := Add(root, "First", AddOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	broken := "---\nid: 7\ntitle: [unclosed\n---\nBroken frontmatter\n"
+	if err := os.WriteFile(filepath.Join(Dir(root), "2025-01-01T09-00-00.md"), []byte(broken), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(leasePath(root, 9), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := Add(root, "Second", AddOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != 10 {
+		t.Errorf("ID = %d, want 10 after the broken item's 7 and the lease of 9", item.ID)
+	}
+}
+
+func TestLegacyItemsGetFrontmatter(t *testing.T) {
+	root := t.TempDir()
+	dir := Dir(root)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"2025-01-02T10-00-00.md": "Old idea\nwith details\n",
+		"2025-01-01T09-00-00.md": "Older idea\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	items, err := List(root, Filter{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("List() = %+v", items)
+	}
+	if items[0].Title != "Older idea" || items[0].ID != 1 || items[1].ID != 2 {
+		t.Errorf("legacy items = %+v", items)
+	}
+	if items[0].CreatedAt.Year() != 2025 {
+		t.Errorf("CreatedAt = %v, want parsed from filename", items[0].CreatedAt)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "2025-01-02T10-00-00.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), "---\nid: 2\n") {
+		t.Errorf("legacy file not upgraded:\n%s", content)
+	}
+	if err := Validate(filepath.Join(dir, "2025-01-02T10-00-00.md")); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestParseID(t *testing.T) {
+	for _, s := range []string{"7", "#7"} {
+		if id, err := ParseID(s); err != nil || id != 7 {
+			t.Errorf("ParseID(%q) = %d, %v", s, id, err)
+		}
+	}
+	for _, s := range []string{"", "0", "x", "#-1"} {
+		if _, err := ParseID(s); err == nil {
+			t.Errorf("ParseID(%q) succeeded", s)
+		}
+	}
+}
+
+func TestClaimReopenArchive(t *testing.T) {
+	root := t.TempDir()
+	item, err := Add(root, "Add dark mode", AddOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claimed, err := Claim(root, item.ID, "dark-mode", 0)
+	if err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	if claimed.Status != StatusClaimed || claimed.Workspace != "dark-mode" {
+		t.Errorf("claimed = %+v", claimed)
+	}
+	if _, err := Claim(root, item.ID, "other", 0); err == nil {
+		t.Error("Claim() succeeded on a claimed item")
+	}
+
+	reopened, err := Reopen(root, item.ID)
+	if err != nil {
+		t.Fatalf("Reopen() error = %v", err)
+	}
+	if reopened.Status != StatusOpen || reopened.Workspace != "" {
+		t.Errorf("reopened = %+v", reopened)
+	}
+
+	if _, err := Claim(root, item.ID, "dark-mode", 0); err != nil {
+		t.Fatalf("Claim() after reopen error = %v", err)
+	}
+	archived, err := Archive(root, item.ID)
+	if err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	if archived.Status != StatusArchived || archived.Workspace != "dark-mode" {
+		t.Errorf("archived = %+v", archived)
+	}
+
+	open, err := List(root, Filter{Status: StatusOpen})
+	if err != nil || len(open) != 0 {
+		t.Errorf("List(open) = %v, %v; want none", open, err)
+	}
+}
@@ -0,0 +1,475 @@
+package queue
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"planq.dev/planq/internal/frontmatter"
+)
+
+// Format is a file format items can be imported from or exported to.
+type Format string
+
+const (
+	// FormatJSONL is one JSON object per line, as written by Export.
+	FormatJSONL Format = "jsonl"
+	// FormatMarkdown is a markdown task list, one item per "- [ ]" task.
+	FormatMarkdown Format = "md"
+	// FormatBeads is a Beads issue log (.beads/*.jsonl). Import only.
+	FormatBeads Format = "beads"
+	// FormatTasuku is a Tasuku task directory (.tasuku/). Import only.
+	FormatTasuku Format = "tasuku"
+)
+
+// ImportFormats lists the formats Parse understands.
+var ImportFormats = []Format{FormatJSONL, FormatMarkdown, FormatBeads, FormatTasuku}
+
+// ExportFormats lists the formats Export writes.
+var ExportFormats = []Format{FormatJSONL, FormatMarkdown}
+
+// ParseFormat validates a format name.
+func ParseFormat(s string) (Format, error) {
+	for _, format := range ImportFormats {
+		if string(format) == s {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown format %q (available: %v)", s, ImportFormats)
+}
+
+// DetectFormat guesses the format of path from its name and location.
+func DetectFormat(path string) (Format, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	for dir := abs; dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		switch filepath.Base(dir) {
+		case ".beads":
+			return FormatBeads, nil
+		case ".tasuku":
+			return FormatTasuku, nil
+		}
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	case ".md", ".markdown":
+		return FormatMarkdown, nil
+	}
+	return "", fmt.Errorf("cannot tell the format of %s, use --format", path)
+}
+
+// SniffFormat guesses the format of content read from a stream.
+func SniffFormat(content []byte) Format {
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte("{")) {
+		return FormatJSONL
+	}
+	return FormatMarkdown
+}
+
+// Draft is an item read from another format that has not been queued yet.
+type Draft struct {
+	Text string
+	AddOptions
+	// Status defaults to StatusOpen.
+	Status Status
+	// CreatedAt defaults to the time of the import.
+	CreatedAt time.Time
+}
+
+// ImportResult reports what Import did.
+type ImportResult struct {
+	Added []*Item
+	// Duplicates are drafts already in the queue or earlier in the import.
+	Duplicates []Draft
+}
+
+// Import adds drafts to the queue, skipping any whose title and text match
+// an existing item or an earlier draft.
+func Import(projectRoot string, drafts []Draft) (*ImportResult, error) {
+	result := &ImportResult{}
+	if len(drafts) == 0 {
+		return result, nil
+	}
+
+	err := withQueue(projectRoot, func(items []*Item) error {
+		seen := make(map[string]bool)
+		for _, item := range items {
+			seen[dedupeKey(item.Title, item.Body)] = true
+		}
+
+		for _, draft := range drafts {
+			title := draft.Title
+			if title == "" {
+				title = deriveTitle(draft.Text)
+			}
+			key := dedupeKey(title, draft.Text)
+			if seen[key] {
+				result.Duplicates = append(result.Duplicates, draft)
+				continue
+			}
+			seen[key] = true
+
+			item, err := add(projectRoot, items, draft.Text, draft.AddOptions)
+			if err != nil {
+				return err
+			}
+			if (draft.Status != "" && draft.Status != item.Status) || !draft.CreatedAt.IsZero() {
+				if draft.Status != "" {
+					item.Status = draft.Status
+				}
+				if !draft.CreatedAt.IsZero() {
+					item.CreatedAt = draft.CreatedAt
+				}
+				if err := write(projectRoot, item); err != nil {
+					return err
+				}
+			}
+			items = append(items, item)
+			result.Added = append(result.Added, item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// dedupeKey identifies an item by its title and text, ignoring case and
+// spacing. A first line of text that repeats the title is ignored, so an item
+// matches its export whether or not the body starts with the title.
+func dedupeKey(title, text string) string {
+	normalize := func(s string) string {
+		return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	}
+	if first, rest, _ := strings.Cut(strings.TrimSpace(text), "\n"); normalize(strings.TrimLeft(first, "# ")) == normalize(title) {
+		text = rest
+	}
+	return normalize(title) + "\x00" + normalize(text)
+}
+
+// ParseFile reads drafts from a file or, for Tasuku, a directory.
+func ParseFile(path string, format Format) ([]Draft, error) {
+	if format == FormatTasuku {
+		return parseTasuku(path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return Parse(content, format)
+}
+
+// Parse reads drafts from content in the given format.
+func Parse(content []byte, format Format) ([]Draft, error) {
+	switch format {
+	case FormatJSONL:
+		return parseJSONL(content, jsonlDraft)
+	case FormatBeads:
+		return parseJSONL(content, beadsDraft)
+	case FormatMarkdown:
+		return parseTaskList(content), nil
+	case FormatTasuku:
+		draft, err := parseTasukuTask(content)
+		if err != nil {
+			return nil, err
+		}
+		return []Draft{draft}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q (available: %v)", format, ImportFormats)
+}
+
+// record is a JSONL line. It covers the fields written by Export and the
+// Beads issue fields planq has a use for.
+type record struct {
+	Title       string      `json:"title"`
+	Body        string      `json:"body"`
+	Text        string      `json:"text"`
+	Description string      `json:"description"`
+	Priority    json.Number `json:"priority"`
+	Tags        []string    `json:"tags"`
+	Labels      []string    `json:"labels"`
+	Status      string      `json:"status"`
+	Source      string      `json:"source"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// parseJSONL decodes one record per non-empty line and converts it with fn.
+func parseJSONL(content []byte, fn func(rec *record) (Draft, error)) ([]Draft, error) {
+	var drafts []Draft
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		var rec record
+		if err := json.Unmarshal(text, &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		draft, err := fn(&rec)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		drafts = append(drafts, draft)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read lines: %w", err)
+	}
+	return drafts, nil
+}
+
+// jsonlDraft converts a record written by Export, or by hand.
+func jsonlDraft(rec *record) (Draft, error) {
+	priority := 0
+	if rec.Priority != "" {
+		p, err := strconv.Atoi(rec.Priority.String())
+		if err != nil {
+			return Draft{}, fmt.Errorf("invalid priority %q", rec.Priority)
+		}
+		if err := ValidatePriority(p); err != nil {
+			return Draft{}, err
+		}
+		priority = p
+	}
+	return rec.draft(priority)
+}
+
+// beadsDraft converts a Beads issue, whose priorities run from 0 (highest) to 4.
+func beadsDraft(rec *record) (Draft, error) {
+	priority := 0
+	if rec.Priority != "" {
+		p, err := strconv.Atoi(rec.Priority.String())
+		if err != nil {
+			return Draft{}, fmt.Errorf("invalid priority %q", rec.Priority)
+		}
+		priority = min(max(p+1, HighestPriority), LowestPriority)
+	}
+	return rec.draft(priority)
+}
+
+// draft builds a draft from the record's fields.
+func (rec *record) draft(priority int) (Draft, error) {
+	text := firstNonEmpty(rec.Body, rec.Text, rec.Description, rec.Title)
+	if strings.TrimSpace(text) == "" {
+		return Draft{}, fmt.Errorf("item has no title or text")
+	}
+
+	return Draft{
+		Text: text,
+		AddOptions: AddOptions{
+			Title:    rec.Title,
+			Priority: priority,
+			Tags:     append(rec.Tags, rec.Labels...),
+			Source:   rec.Source,
+		},
+		Status:    importStatus(rec.Status),
+		CreatedAt: rec.CreatedAt,
+	}, nil
+}
+
+// importStatus maps the statuses of other tools onto planq's. Work in
+// progress elsewhere is not claimed by any workspace here, so it is open.
+func importStatus(s string) Status {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "done", "closed", "completed", "complete", "resolved":
+		return StatusDone
+	case "archived":
+		return StatusArchived
+	}
+	return StatusOpen
+}
+
+// taskPattern matches a markdown task list entry.
+var taskPattern = regexp.MustCompile(`^(\s*)[-*+] \[([ xX])\]\s+(.*)$`)
+
+// parseTaskList turns every task of a markdown task list into a draft.
+// Lines indented below a task that are not tasks themselves become its text.
+func parseTaskList(content []byte) []Draft {
+	var drafts []Draft
+	var current *Draft
+	indent := 0
+
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimRight(line, " \r")
+		if m := taskPattern.FindStringSubmatch(line); m != nil {
+			drafts = append(drafts, Draft{AddOptions: AddOptions{Title: m[3]}, Status: StatusOpen})
+			current = &drafts[len(drafts)-1]
+			indent = len(m[1])
+			if m[2] != " " {
+				current.Status = StatusDone
+			}
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+		if line == "" {
+			current.Text += "\n"
+			continue
+		}
+		trimmed := strings.TrimLeft(line, " \t")
+		if len(line)-len(trimmed) <= indent {
+			current = nil // Back at the task's level, the task is over
+			continue
+		}
+		current.Text += "\n" + trimmed
+	}
+
+	// A task without indented lines is its own text
+	for i := range drafts {
+		drafts[i].Text = firstNonEmpty(strings.TrimSpace(drafts[i].Text), drafts[i].Title)
+	}
+	return drafts
+}
+
+// tasukuTask is the frontmatter of a Tasuku task file.
+type tasukuTask struct {
+	Title    string   `yaml:"title"`
+	Status   string   `yaml:"status"`
+	Priority string   `yaml:"priority"`
+	Tags     []string `yaml:"tags"`
+	Labels   []string `yaml:"labels"`
+}
+
+// tasukuPriorities maps Tasuku's named priorities onto planq's.
+var tasukuPriorities = map[string]int{
+	"critical": 1,
+	"urgent":   1,
+	"high":     2,
+	"medium":   3,
+	"normal":   3,
+	"low":      4,
+}
+
+// parseTasuku reads every task file below a Tasuku directory.
+func parseTasuku(dir string) ([]Draft, error) {
+	var drafts []Draft
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".md" {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		draft, err := parseTasukuTask(content)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		drafts = append(drafts, draft)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return drafts, nil
+}
+
+// parseTasukuTask converts one Tasuku task file.
+func parseTasukuTask(content []byte) (Draft, error) {
+	var task tasukuTask
+	body, err := frontmatter.Parse(content, &task)
+	if err != nil {
+		return Draft{}, err
+	}
+
+	text := firstNonEmpty(strings.TrimSpace(body), task.Title)
+	if text == "" {
+		return Draft{}, fmt.Errorf("task has no title or text")
+	}
+
+	priority := 0
+	if p, err := strconv.Atoi(task.Priority); err == nil {
+		priority = min(max(p, HighestPriority), LowestPriority)
+	} else if p, ok := tasukuPriorities[strings.ToLower(task.Priority)]; ok {
+		priority = p
+	}
+
+	return Draft{
+		Text: text,
+		AddOptions: AddOptions{
+			Title:    task.Title,
+			Priority: priority,
+			Tags:     append(task.Tags, task.Labels...),
+		},
+		Status: importStatus(task.Status),
+	}, nil
+}
+
+// Export writes items to w in the given format.
+func Export(w io.Writer, items []Item, format Format) error {
+	switch format {
+	case FormatJSONL:
+		enc := json.NewEncoder(w)
+		for _, item := range items {
+			if err := enc.Encode(item); err != nil {
+				return fmt.Errorf("failed to write item #%d: %w", item.ID, err)
+			}
+		}
+		return nil
+	case FormatMarkdown:
+		for _, item := range items {
+			if _, err := io.WriteString(w, taskListEntry(&item)); err != nil {
+				return fmt.Errorf("failed to write item #%d: %w", item.ID, err)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("cannot export to %q (available: %v)", format, ExportFormats)
+}
+
+// taskListEntry renders an item as a markdown task with its text indented below.
+func taskListEntry(item *Item) string {
+	check := " "
+	if item.Status == StatusDone || item.Status == StatusArchived {
+		check = "x"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "- [%s] %s\n", check, item.Title)
+	body := item.Body
+	if first, rest, _ := strings.Cut(body, "\n"); strings.TrimSpace(strings.TrimLeft(first, "# ")) == item.Title {
+		body = strings.TrimSpace(rest) // the title is the first line of the body
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if line = strings.TrimRight(line, " "); line != "" {
+			sb.WriteString("  " + line)
+		}
+		if body != "" {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// firstNonEmpty returns the first of values that is not blank.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,194 @@
+package queue
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTaskList(t *testing.T) {
+	content := []byte(`# Backlog
+
+- [ ] Fix the login redirect
+  It loops forever on Safari.
+- [x] Bump Go
+* [ ] Write docs
+    - [ ] Nested task
+
+Some closing prose.
+`)
+
+	drafts, err := Parse(content, FormatMarkdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drafts) != 4 {
+		t.Fatalf("got %d drafts, want 4: %+v", len(drafts), drafts)
+	}
+	if drafts[0].Title != "Fix the login redirect" || drafts[0].Text != "It loops forever on Safari." || drafts[0].Status != StatusOpen {
+		t.Errorf("drafts[0] = %+v", drafts[0])
+	}
+	if drafts[1].Text != "Bump Go" || drafts[1].Status != StatusDone {
+		t.Errorf("drafts[1] = %+v", drafts[1])
+	}
+	if drafts[3].Text != "Nested task" {
+		t.Errorf("drafts[3] = %+v", drafts[3])
+	}
+}
+
+func TestParseBeads(t *testing.T) {
+	content := []byte(`{"id":"bd-a1b2","title":"Crash on start","description":"Stack trace attached","status":"in_progress","priority":0,"labels":["bug"]}
+{"id":"bd-c3d4","title":"Old cleanup","status":"closed","priority":4}
+`)
+
+	drafts, err := Parse(content, FormatBeads)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drafts) != 2 {
+		t.Fatalf("got %d drafts, want 2", len(drafts))
+	}
+	first := drafts[0]
+	if first.Title != "Crash on start" || first.Text != "Stack trace attached" || first.Priority != 1 || first.Status != StatusOpen || len(first.Tags) != 1 {
+		t.Errorf("drafts[0] = %+v", first)
+	}
+	if drafts[1].Priority != 5 || drafts[1].Status != StatusDone {
+		t.Errorf("drafts[1] = %+v", drafts[1])
+	}
+
+	if _, err := Parse([]byte("{not json\n"), FormatJSONL); err == nil {
+		t.Error("Parse() accepted a broken line")
+	}
+	if _, err := Parse([]byte(`{"title":"x","priority":9}`), FormatJSONL); err == nil {
+		t.Error("Parse() accepted priority 9")
+	}
+}
+
+func TestParseTasuku(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".tasuku")
+	if err := os.MkdirAll(filepath.Join(dir, "tasks"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	task := "---\ntitle: Add caching\nstatus: ready\npriority: high\ntags: [perf]\n---\n\nCache the API responses.\n"
+	if err := os.WriteFile(filepath.Join(dir, "tasks", "add-caching.md"), []byte(task), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	format, err := DetectFormat(filepath.Join(dir, "tasks"))
+	if err != nil || format != FormatTasuku {
+		t.Fatalf("DetectFormat() = %q, %v", format, err)
+	}
+	drafts, err := ParseFile(dir, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drafts) != 1 {
+		t.Fatalf("got %d drafts, want 1", len(drafts))
+	}
+	if d := drafts[0]; d.Title != "Add caching" || d.Priority != 2 || d.Status != StatusOpen || d.Text != "Cache the API responses." {
+		t.Errorf("draft = %+v", d)
+	}
+}
+
+func TestImportDedupesAndExportRoundTrips(t *testing.T) {
+	root := t.TempDir()
+	if _, err := Add(root, "Fix the login redirect\n\nIt loops forever.", AddOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	drafts, err := Parse([]byte("- [ ] Fix the login redirect\n  It loops forever.\n- [ ] Bump Go\n- [ ] bump  go\n"), FormatMarkdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := Import(root, drafts)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(result.Added) != 1 || result.Added[0].Title != "Bump Go" || result.Added[0].ID != 2 {
+		t.Errorf("Added = %+v", result.Added)
+	}
+	if len(result.Duplicates) != 2 {
+		t.Errorf("Duplicates = %+v, want 2", result.Duplicates)
+	}
+
+	items, err := List(root, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range ExportFormats {
+		var buf bytes.Buffer
+		if err := Export(&buf, items, format); err != nil {
+			t.Fatalf("Export(%s) error = %v", format, err)
+		}
+		exported, err := Parse(buf.Bytes(), format)
+		if err != nil {
+			t.Fatalf("Parse(%s) error = %v", format, err)
+		}
+		again, err := Import(root, exported)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(again.Added) != 0 {
+			t.Errorf("re-importing %s added %+v", format, again.Added)
+		}
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	texts := []struct {
+		text  string
+		title string
+	}{
+		{"Fix the login redirect\n\nIt loops forever.", ""},
+		{"It loops forever on Safari.\n\nSee the HAR file.", "Safari redirect loop"},
+		{"Bump Go", ""},
+	}
+	for _, tt := range texts {
+		if _, err := Add(root, tt.text, AddOptions{Title: tt.title, Tags: []string{"web"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	items, err := List(root, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range ExportFormats {
+		var buf bytes.Buffer
+		if err := Export(&buf, items, format); err != nil {
+			t.Fatalf("Export(%s) error = %v", format, err)
+		}
+		drafts, err := Parse(buf.Bytes(), format)
+		if err != nil {
+			t.Fatalf("Parse(%s) error = %v", format, err)
+		}
+		result, err := Import(root, drafts)
+		if err != nil {
+			t.Fatalf("Import(%s) error = %v", format, err)
+		}
+		if len(result.Added) != 0 || len(result.Duplicates) != len(items) {
+			t.Errorf("%s: added %d, duplicates %d, want 0 and %d", format, len(result.Added), len(result.Duplicates), len(items))
+		}
+	}
+
+	// Importing into an empty queue keeps titles and bodies apart
+	var buf bytes.Buffer
+	if err := Export(&buf, items, FormatJSONL); err != nil {
+		t.Fatal(err)
+	}
+	drafts, err := Parse(buf.Bytes(), FormatJSONL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := Import(t.TempDir(), drafts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, item := range result.Added {
+		if item.Title != items[i].Title || item.Body != items[i].Body {
+			t.Errorf("imported %q / %q, want %q / %q", item.Title, item.Body, items[i].Title, items[i].Body)
+		}
+	}
+}
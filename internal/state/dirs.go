@@ -0,0 +1,152 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"planq.dev/planq/internal/git"
+)
+
+const (
+	// HomeEnv overrides both the state and config directories.
+	HomeEnv = "PLANQ_HOME"
+	// appDirName is the planq directory name under the XDG base directories.
+	appDirName = "planq"
+	// legacyDirName is the directory under $HOME used before XDG support.
+	legacyDirName = ".planq"
+	// workspaceFileName marks a .planq directory that belongs to a workspace.
+	workspaceFileName = "workspace.json"
+)
+
+// legacyConfigEntries are the user config files kept in ~/.planq before XDG support.
+var legacyConfigEntries = []string{"config.yaml", "templates"}
+
+// StateDir returns the directory holding the global state file:
+// $PLANQ_HOME, else $XDG_STATE_HOME/planq, else ~/.local/state/planq.
+// State left in the legacy ~/.planq directory is moved there on first use.
+func StateDir() (string, error) {
+	if dir := os.Getenv(HomeEnv); dir != "" {
+		return dir, nil
+	}
+
+	dir, err := xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+	if err != nil {
+		return "", err
+	}
+	if err := migrateLegacyState(dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// ConfigDir returns the directory holding user config and templates:
+// $PLANQ_HOME, else $XDG_CONFIG_HOME/planq, else ~/.config/planq.
+// Config left in the legacy ~/.planq directory is moved there on first use.
+func ConfigDir() (string, error) {
+	if dir := os.Getenv(HomeEnv); dir != "" {
+		return dir, nil
+	}
+
+	dir, err := xdgDir("XDG_CONFIG_HOME", ".config")
+	if err != nil {
+		return "", err
+	}
+	if err := migrateLegacyConfig(dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// xdgDir returns the planq directory under the XDG base directory in env,
+// falling back to def relative to the home directory.
+func xdgDir(env, def string) (string, error) {
+	// The spec says relative paths are invalid and must be ignored
+	if base := os.Getenv(env); filepath.IsAbs(base) {
+		return filepath.Join(base, appDirName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, def, appDirName), nil
+}
+
+// legacyDir returns the pre-XDG ~/.planq directory.
+func legacyDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, legacyDirName), nil
+}
+
+// migrateLegacyState moves the state file and its migration backups from ~/.planq to dir.
+func migrateLegacyState(dir string) error {
+	legacy, err := legacyDir()
+	if err != nil {
+		return err
+	}
+
+	backups, err := filepath.Glob(filepath.Join(legacy, stateFileName+".v*.bak"))
+	if err != nil {
+		return fmt.Errorf("failed to list legacy state backups: %w", err)
+	}
+	for _, path := range append([]string{filepath.Join(legacy, stateFileName)}, backups...) {
+		if err := moveLegacy(path, filepath.Join(dir, filepath.Base(path))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateLegacyConfig moves user config and templates from ~/.planq to dir.
+// If the home directory is a repository (e.g. dotfiles), ~/.planq holds that
+// repository's config and stays put.
+func migrateLegacyConfig(dir string) error {
+	legacy, err := legacyDir()
+	if err != nil {
+		return err
+	}
+
+	var found []string
+	for _, name := range legacyConfigEntries {
+		if _, err := os.Lstat(filepath.Join(legacy, name)); err == nil {
+			found = append(found, name)
+		}
+	}
+	if len(found) == 0 {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(legacy, workspaceFileName)); err == nil {
+		return nil
+	}
+	if git.IsInsideWorkTree(filepath.Dir(legacy)) {
+		return nil
+	}
+
+	for _, name := range found {
+		if err := moveLegacy(filepath.Join(legacy, name), filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveLegacy moves src to dst unless src is missing or dst already exists.
+func moveLegacy(src, dst string) error {
+	if _, err := os.Lstat(src); err != nil {
+		return nil
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(dst), err)
+	}
+	if err := os.Rename(src, dst); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to move %s to %s: %w", src, dst, err)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package state
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PLANQ_HOME", "")
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	tests := []struct {
+		name       string
+		env        map[string]string
+		wantState  string
+		wantConfig string
+	}{
+		{
+			name:       "defaults",
+			wantState:  filepath.Join(home, ".local", "state", "planq"),
+			wantConfig: filepath.Join(home, ".config", "planq"),
+		},
+		{
+			name:       "xdg",
+			env:        map[string]string{"XDG_STATE_HOME": "/xdg/state", "XDG_CONFIG_HOME": "/xdg/config"},
+			wantState:  "/xdg/state/planq",
+			wantConfig: "/xdg/config/planq",
+		},
+		{
+			name:       "relative xdg ignored",
+			env:        map[string]string{"XDG_STATE_HOME": "state", "XDG_CONFIG_HOME": "config"},
+			wantState:  filepath.Join(home, ".local", "state", "planq"),
+			wantConfig: filepath.Join(home, ".config", "planq"),
+		},
+		{
+			name:       "planq home",
+			env:        map[string]string{"PLANQ_HOME": "/planq", "XDG_STATE_HOME": "/xdg/state"},
+			wantState:  "/planq",
+			wantConfig: "/planq",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			if got, err := StateDir(); err != nil || got != tt.wantState {
+				t.Errorf("StateDir() = %q, %v; want %q", got, err, tt.wantState)
+			}
+			if got, err := ConfigDir(); err != nil || got != tt.wantConfig {
+				t.Errorf("ConfigDir() = %q, %v; want %q", got, err, tt.wantConfig)
+			}
+		})
+	}
+}
+
+func TestLegacyMigration(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PLANQ_HOME", "")
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, "state"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+
+	legacy := filepath.Join(home, ".planq")
+	files := map[string]string{
+		"state.json":            `{"version":2,"main_workspaces":{}}`,
+		"state.json.v1.bak":     `{"main_workspaces":{}}`,
+		"config.yaml":           "agent:\n  name: aider\n",
+		"templates/feature.md":  "# {{.Name}}\n",
+		"unrelated/keep-me.txt": "keep",
+	}
+	for name, content := range files {
+		path := filepath.Join(legacy, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stateDir, err := StateDir()
+	if err != nil {
+		t.Fatalf("StateDir() error = %v", err)
+	}
+	configDir, err := ConfigDir()
+	if err != nil {
+		t.Fatalf("ConfigDir() error = %v", err)
+	}
+
+	moved := map[string]string{
+		"state.json":           stateDir,
+		"state.json.v1.bak":    stateDir,
+		"config.yaml":          configDir,
+		"templates/feature.md": configDir,
+	}
+	for name, dir := range moved {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("%s not migrated: %v", name, err)
+			continue
+		}
+		if string(content) != files[name] {
+			t.Errorf("%s = %q, want %q", name, content, files[name])
+		}
+		if _, err := os.Stat(filepath.Join(legacy, name)); !os.IsNotExist(err) {
+			t.Errorf("%s still in legacy directory", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(legacy, "unrelated", "keep-me.txt")); err != nil {
+		t.Errorf("unrelated legacy file was touched: %v", err)
+	}
+
+	// The new location wins once it exists
+	if err := os.WriteFile(filepath.Join(legacy, "state.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if s.Version != CurrentVersion {
+		t.Errorf("Version = %d, want %d", s.Version, CurrentVersion)
+	}
+	if _, err := os.Stat(filepath.Join(legacy, "state.json")); err != nil {
+		t.Errorf("legacy state.json should be left alone once migrated: %v", err)
+	}
+}
+
+func TestLegacyMigrationSkipsWorkspaceConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PLANQ_HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+
+	// The home directory is itself a planq workspace (e.g. a dotfiles repo)
+	legacy := filepath.Join(home, ".planq")
+	if err := os.MkdirAll(legacy, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"workspace.json", "config.yaml"} {
+		if err := os.WriteFile(filepath.Join(legacy, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	configDir, err := ConfigDir()
+	if err != nil {
+		t.Fatalf("ConfigDir() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(configDir, "config.yaml")); !os.IsNotExist(err) {
+		t.Error("workspace config.yaml should not be migrated")
+	}
+	if _, err := os.Stat(filepath.Join(legacy, "config.yaml")); err != nil {
+		t.Errorf("workspace config.yaml was moved: %v", err)
+	}
+}
+
+func TestLegacyMigrationSkipsDotfilesHome(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PLANQ_HOME", "")
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, "state"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+
+	// The home directory is a dotfiles repository that commits ~/.planq
+	if out, err := exec.Command("git", "init", "-q", home).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v: %s", err, out)
+	}
+	legacy := filepath.Join(home, ".planq")
+	if err := os.MkdirAll(filepath.Join(legacy, "templates"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"config.yaml", "state.json"} {
+		if err := os.WriteFile(filepath.Join(legacy, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	configDir, err := ConfigDir()
+	if err != nil {
+		t.Fatalf("ConfigDir() error = %v", err)
+	}
+	for _, name := range []string{"config.yaml", "templates"} {
+		if _, err := os.Stat(filepath.Join(legacy, name)); err != nil {
+			t.Errorf("repository %s was moved: %v", name, err)
+		}
+		if _, err := os.Stat(filepath.Join(configDir, name)); !os.IsNotExist(err) {
+			t.Errorf("repository %s should not be migrated", name)
+		}
+	}
+
+	// State is planq's own and still moves
+	stateDir, err := StateDir()
+	if err != nil {
+		t.Fatalf("StateDir() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(stateDir, "state.json")); err != nil {
+		t.Errorf("state.json was not migrated: %v", err)
+	}
+}
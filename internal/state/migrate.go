@@ -0,0 +1,140 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"planq.dev/planq/internal/statefile"
+)
+
+// CurrentVersion is the state file schema version written by this planq.
+//
+// Version history:
+//   - 1: main_workspaces only (files without a version field)
+//   - 2: adds the workspaces registry
+const CurrentVersion = 2
+
+// ErrUnsupportedVersion is returned when the state file was written by a newer planq.
+var ErrUnsupportedVersion = errors.New("unsupported state file version")
+
+// legacySessionPrefix is the tmux session prefix used when migrating
+// main workspaces into the registry.
+const legacySessionPrefix = "planq-"
+
+// migration upgrades the raw state document by one version.
+type migration func(doc map[string]json.RawMessage) error
+
+// migrations[v] upgrades a version v document to version v+1.
+var migrations = map[int]migration{
+	1: migrateV1,
+}
+
+// migrateV1 registers the main workspaces of a version 1 file in the workspace registry.
+func migrateV1(doc map[string]json.RawMessage) error {
+	var mains map[string]MainWorkspaceEntry
+	if raw, ok := doc["main_workspaces"]; ok {
+		if err := json.Unmarshal(raw, &mains); err != nil {
+			return fmt.Errorf("failed to parse main workspaces: %w", err)
+		}
+	}
+
+	workspaces := make(map[string]WorkspaceEntry)
+	if raw, ok := doc["workspaces"]; ok {
+		if err := json.Unmarshal(raw, &workspaces); err != nil {
+			return fmt.Errorf("failed to parse workspaces: %w", err)
+		}
+	}
+	for repoPath, entry := range mains {
+		if _, exists := workspaces[repoPath]; exists {
+			continue
+		}
+		workspaces[repoPath] = WorkspaceEntry{
+			Name:         entry.Name,
+			RepoPath:     repoPath,
+			WorktreePath: repoPath,
+			SessionName:  legacySessionPrefix + entry.Name,
+		}
+	}
+
+	raw, err := json.Marshal(workspaces)
+	if err != nil {
+		return fmt.Errorf("failed to marshal workspaces: %w", err)
+	}
+	doc["workspaces"] = raw
+	return nil
+}
+
+// fileVersion returns the schema version of a decoded state document.
+// Files written before versioning are version 1.
+func fileVersion(doc map[string]json.RawMessage) (int, error) {
+	raw, ok := doc["version"]
+	if !ok {
+		return 1, nil
+	}
+	var version int
+	if err := json.Unmarshal(raw, &version); err != nil {
+		return 0, fmt.Errorf("failed to parse state file version: %w", err)
+	}
+	return version, nil
+}
+
+// migrate upgrades state file contents to CurrentVersion.
+// It returns the upgraded contents and the version the file was at.
+func migrate(data []byte) ([]byte, int, error) {
+	if len(data) == 0 {
+		return data, CurrentVersion, nil
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse state file: %w", err)
+	}
+
+	from, err := fileVersion(doc)
+	if err != nil {
+		return nil, 0, err
+	}
+	if from > CurrentVersion {
+		return nil, from, fmt.Errorf("%w: state file is version %d but this planq supports up to version %d; upgrade planq", ErrUnsupportedVersion, from, CurrentVersion)
+	}
+	if from == CurrentVersion {
+		return data, from, nil
+	}
+
+	for v := from; v < CurrentVersion; v++ {
+		m, ok := migrations[v]
+		if !ok {
+			return nil, from, fmt.Errorf("%w: no migration from state file version %d", ErrUnsupportedVersion, v)
+		}
+		if err := m(doc); err != nil {
+			return nil, from, fmt.Errorf("failed to migrate state file from version %d: %w", v, err)
+		}
+	}
+
+	doc["version"] = json.RawMessage(fmt.Sprint(CurrentVersion))
+	upgraded, err := json.Marshal(doc)
+	if err != nil {
+		return nil, from, fmt.Errorf("failed to marshal migrated state: %w", err)
+	}
+	return upgraded, from, nil
+}
+
+// BackupFile returns the path of the backup kept when migrating from version.
+func BackupFile(stateFile string, version int) string {
+	return fmt.Sprintf("%s.v%d.bak", stateFile, version)
+}
+
+// backup saves the pre-migration contents of the state file.
+// An existing backup is kept, since it holds the oldest copy.
+func backup(stateFile string, data []byte, version int) error {
+	path := BackupFile(stateFile, version)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := statefile.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to back up state file: %w", err)
+	}
+	return nil
+}
@@ -5,16 +5,20 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-)
+	"sort"
 
-const (
-	stateDirName  = ".planq"
-	stateFileName = "state.json"
+	"planq.dev/planq/internal/statefile"
 )
 
+const stateFileName = "state.json"
+
 // GlobalState tracks planq state across repositories.
 type GlobalState struct {
+	// Version is the schema version of the state file; see CurrentVersion.
+	Version        int                           `json:"version"`
 	MainWorkspaces map[string]MainWorkspaceEntry `json:"main_workspaces"`
+	// Workspaces registers every workspace across repositories, keyed by worktree path.
+	Workspaces map[string]WorkspaceEntry `json:"workspaces,omitempty"`
 }
 
 // MainWorkspaceEntry tracks a main workspace for a repository.
@@ -23,13 +27,12 @@ type MainWorkspaceEntry struct {
 	RepoPath string `json:"repo_path"`
 }
 
-// StateDir returns the path to the global planq state directory.
-func StateDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
-	}
-	return filepath.Join(home, stateDirName), nil
+// WorkspaceEntry registers a workspace in the global state.
+type WorkspaceEntry struct {
+	Name         string `json:"name"`
+	RepoPath     string `json:"repo_path"`
+	WorktreePath string `json:"worktree_path"`
+	SessionName  string `json:"session_name"`
 }
 
 // StateFile returns the path to the global state file.
@@ -42,6 +45,7 @@ func StateFile() (string, error) {
 }
 
 // Load reads the global state from disk.
+// Files written by an older planq are migrated in place, keeping a backup.
 func Load() (*GlobalState, error) {
 	stateFile, err := StateFile()
 	if err != nil {
@@ -51,46 +55,82 @@ func Load() (*GlobalState, error) {
 	data, err := os.ReadFile(stateFile)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return &GlobalState{MainWorkspaces: make(map[string]MainWorkspaceEntry)}, nil
+			state, _, err := parse(nil)
+			return state, err
 		}
 		return nil, fmt.Errorf("failed to read state file: %w", err)
 	}
 
-	var state GlobalState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return nil, fmt.Errorf("failed to parse state file: %w", err)
+	state, from, err := parse(data)
+	if err != nil {
+		return nil, err
 	}
-	if state.MainWorkspaces == nil {
-		state.MainWorkspaces = make(map[string]MainWorkspaceEntry)
+	if from < CurrentVersion {
+		// Persist the upgrade so the backup is only taken once
+		if err := Update(func(*GlobalState) error { return nil }); err != nil {
+			return nil, err
+		}
 	}
-	return &state, nil
+	return state, nil
 }
 
-// Save writes the global state to disk.
-func (s *GlobalState) Save() error {
-	stateDir, err := StateDir()
+// parse decodes the global state file contents, migrating older schemas.
+// It also returns the version the contents were written at.
+func parse(data []byte) (*GlobalState, int, error) {
+	upgraded, from, err := migrate(data)
 	if err != nil {
-		return err
+		return nil, from, err
 	}
 
-	if err := os.MkdirAll(stateDir, 0755); err != nil {
-		return fmt.Errorf("failed to create state directory: %w", err)
+	var state GlobalState
+	if len(upgraded) > 0 {
+		if err := json.Unmarshal(upgraded, &state); err != nil {
+			return nil, from, fmt.Errorf("failed to parse state file: %w", err)
+		}
 	}
-
-	stateFile, err := StateFile()
-	if err != nil {
-		return err
+	state.Version = CurrentVersion
+	if state.MainWorkspaces == nil {
+		state.MainWorkspaces = make(map[string]MainWorkspaceEntry)
 	}
+	if state.Workspaces == nil {
+		state.Workspaces = make(map[string]WorkspaceEntry)
+	}
+	return &state, from, nil
+}
 
+// marshal encodes the global state for writing.
+func (s *GlobalState) marshal() ([]byte, error) {
+	s.Version = CurrentVersion
 	data, err := json.MarshalIndent(s, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal state: %w", err)
+		return nil, fmt.Errorf("failed to marshal state: %w", err)
 	}
+	return data, nil
+}
 
-	if err := os.WriteFile(stateFile, data, 0644); err != nil {
-		return fmt.Errorf("failed to write state file: %w", err)
+// Update loads the global state, applies fn and saves the result while holding
+// the state file lock. If fn returns an error nothing is written.
+func Update(fn func(s *GlobalState) error) error {
+	stateFile, err := StateFile()
+	if err != nil {
+		return err
 	}
-	return nil
+
+	return statefile.Update(stateFile, 0644, func(data []byte) ([]byte, error) {
+		state, from, err := parse(data)
+		if err != nil {
+			return nil, err
+		}
+		if from < CurrentVersion {
+			if err := backup(stateFile, data, from); err != nil {
+				return nil, err
+			}
+		}
+		if err := fn(state); err != nil {
+			return nil, err
+		}
+		return state.marshal()
+	})
 }
 
 // HasMainWorkspace checks if a main workspace exists for the given repo.
@@ -136,3 +176,27 @@ func (s *GlobalState) GetMainWorkspaceNames() map[string]bool {
 	}
 	return names
 }
+
+// RegisterWorkspace records a workspace in the registry.
+func (s *GlobalState) RegisterWorkspace(entry WorkspaceEntry) {
+	s.Workspaces[entry.WorktreePath] = entry
+}
+
+// UnregisterWorkspace removes the registry entry for a worktree path.
+func (s *GlobalState) UnregisterWorkspace(worktreePath string) {
+	delete(s.Workspaces, worktreePath)
+}
+
+// FindWorkspacesByName returns all registered workspaces with the given name.
+func (s *GlobalState) FindWorkspacesByName(name string) []WorkspaceEntry {
+	var entries []WorkspaceEntry
+	for _, entry := range s.Workspaces {
+		if entry.Name == name {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].WorktreePath < entries[j].WorktreePath
+	})
+	return entries
+}
@@ -0,0 +1,138 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeStateFile points PLANQ_HOME at a temp dir and writes the given state file.
+func writeStateFile(t *testing.T, content string) string {
+	t.Helper()
+	t.Setenv("PLANQ_HOME", t.TempDir())
+
+	stateFile, err := StateFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content == "" {
+		return stateFile
+	}
+	if err := os.MkdirAll(filepath.Dir(stateFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stateFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return stateFile
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	writeStateFile(t, "")
+
+	s, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if s.Version != CurrentVersion {
+		t.Errorf("Version = %d, want %d", s.Version, CurrentVersion)
+	}
+	if s.MainWorkspaces == nil || s.Workspaces == nil {
+		t.Error("Load() should initialize maps")
+	}
+}
+
+func TestLoadMigratesLegacyFile(t *testing.T) {
+	legacy := `{"main_workspaces":{"/repo":{"name":"main-ws","repo_path":"/repo"}}}`
+	stateFile := writeStateFile(t, legacy)
+
+	s, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if s.Version != CurrentVersion {
+		t.Errorf("Version = %d, want %d", s.Version, CurrentVersion)
+	}
+	if entry, ok := s.GetMainWorkspace("/repo"); !ok || entry.Name != "main-ws" {
+		t.Errorf("main workspace = %+v, %v", entry, ok)
+	}
+	entry, ok := s.Workspaces["/repo"]
+	if !ok {
+		t.Fatal("main workspace was not registered")
+	}
+	if entry.Name != "main-ws" || entry.SessionName != "planq-main-ws" {
+		t.Errorf("registry entry = %+v", entry)
+	}
+
+	// The original file is kept as a backup
+	backup, err := os.ReadFile(BackupFile(stateFile, 1))
+	if err != nil {
+		t.Fatalf("backup not written: %v", err)
+	}
+	if string(backup) != legacy {
+		t.Errorf("backup = %q, want %q", backup, legacy)
+	}
+
+	// The upgraded file is written back
+	data, err := os.ReadFile(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upgraded, from, err := parse(data)
+	if err != nil {
+		t.Fatalf("parse() error = %v", err)
+	}
+	if from != CurrentVersion {
+		t.Errorf("file version = %d, want %d", from, CurrentVersion)
+	}
+	if len(upgraded.Workspaces) != 1 {
+		t.Errorf("Workspaces = %v, want 1 entry", upgraded.Workspaces)
+	}
+}
+
+func TestLoadNewerVersion(t *testing.T) {
+	newer := `{"version":99,"main_workspaces":{}}`
+	stateFile := writeStateFile(t, newer)
+
+	if _, err := Load(); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("Load() error = %v, want ErrUnsupportedVersion", err)
+	}
+	err := Update(func(s *GlobalState) error {
+		s.SetMainWorkspace("/repo", "ws")
+		return nil
+	})
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("Update() error = %v, want ErrUnsupportedVersion", err)
+	}
+
+	data, err := os.ReadFile(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != newer {
+		t.Errorf("state file was modified: %q", data)
+	}
+}
+
+func TestUpdateRegistersWorkspace(t *testing.T) {
+	writeStateFile(t, "")
+
+	err := Update(func(s *GlobalState) error {
+		s.RegisterWorkspace(WorkspaceEntry{Name: "ws", RepoPath: "/repo", WorktreePath: "/wt/b"})
+		s.RegisterWorkspace(WorkspaceEntry{Name: "ws", RepoPath: "/other", WorktreePath: "/wt/a"})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	s, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	entries := s.FindWorkspacesByName("ws")
+	if len(entries) != 2 || entries[0].WorktreePath != "/wt/a" {
+		t.Errorf("FindWorkspacesByName() = %+v", entries)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package statefile
+
+import "os"
+
+// lockFile is a no-op on platforms without flock; writes stay atomic but
+// concurrent read-modify-write cycles are not serialized.
+func lockFile(f *os.File) error {
+	return nil
+}
+
+// unlockFile is a no-op on platforms without flock.
+func unlockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,125 @@
+// Package statefile provides atomic writes and advisory locks for planq state files.
+package statefile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// lockSuffix is appended to a path to name its lock file.
+const lockSuffix = ".lock"
+
+// Lock is an advisory lock held on a state file.
+type Lock struct {
+	f *os.File
+}
+
+// Acquire takes an exclusive lock on path, blocking until it is available.
+// The lock is held on a separate "<path>.lock" file so that the state file
+// itself can be replaced by WriteFile while the lock is held.
+func Acquire(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+
+	f, err := os.OpenFile(path+lockSuffix, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+
+	return &Lock{f: f}, nil
+}
+
+// Release releases the lock.
+func (l *Lock) Release() error {
+	if err := unlockFile(l.f); err != nil {
+		l.f.Close()
+		return fmt.Errorf("failed to unlock: %w", err)
+	}
+	return l.f.Close()
+}
+
+// WriteFile atomically replaces path with data by writing to a temporary
+// file in the same directory and renaming it into place.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Update runs a locked read-modify-write of path. fn receives the current
+// contents (nil if the file does not exist) and returns the new contents.
+func Update(path string, perm os.FileMode, fn func(data []byte) ([]byte, error)) error {
+	lock, err := Acquire(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	updated, err := fn(data)
+	if err != nil {
+		return err
+	}
+
+	return WriteFile(path, updated, perm)
+}
+
+// Append appends data to path under the file's lock, creating it if needed.
+func Append(path string, data []byte, perm os.FileMode) error {
+	lock, err := Acquire(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("failed to append to %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package statefile
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	if err := WriteFile(path, []byte("one"), 0644); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+	if err := WriteFile(path, []byte("two"), 0644); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "two" {
+		t.Errorf("ReadFile() = %q, %v, want %q", data, err, "two")
+	}
+
+	// No temp files are left behind
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want 1", len(entries))
+	}
+}
+
+func TestUpdate_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter")
+
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := Update(path, 0644, func(data []byte) ([]byte, error) {
+				n, _ := strconv.Atoi(string(data))
+				return []byte(strconv.Itoa(n + 1)), nil
+			})
+			if err != nil {
+				t.Errorf("Update() failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	if string(data) != strconv.Itoa(writers) {
+		t.Errorf("counter = %s, want %d", data, writers)
+	}
+}
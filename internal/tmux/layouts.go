@@ -28,3 +28,30 @@ func ExecuteLayout(agentCmd string) Layout {
 		},
 	}
 }
+
+// ReviewLayout returns the layout for review mode.
+// 3-pane layout: reviewer agent (left, 40%), diff against HEAD (top-right), terminal (bottom-right)
+func ReviewLayout(agentCmd string) Layout {
+	return Layout{
+		Name:        "review",
+		Description: "Review mode: agent + full diff + terminal",
+		Panes: []PaneSpec{
+			{Name: "agent", Size: 40, Command: agentCmd},
+			{Name: "diff", Size: 45, Command: "while true; do clear; git --no-pager diff --stat HEAD; git diff --color=always HEAD | delta --paging=never; sleep 5; done"},
+			{Name: "terminal", Size: 15, Command: ""},
+		},
+	}
+}
+
+// TestLayout returns the layout for test mode.
+// 2-pane layout: agent (left, 50%) + terminal for running tests (right, 50%)
+func TestLayout(agentCmd string) Layout {
+	return Layout{
+		Name:        "test",
+		Description: "Test mode: agent + test terminal",
+		Panes: []PaneSpec{
+			{Name: "agent", Size: 50, Command: agentCmd},
+			{Name: "terminal", Size: 50, Command: ""},
+		},
+	}
+}
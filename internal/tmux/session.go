@@ -3,7 +3,10 @@ package tmux
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"github.com/GianlucaP106/gotmux/gotmux"
 )
@@ -127,7 +130,7 @@ func (m *Manager) CreateSession(name string, workdir string, layout Layout) (*go
 	// Enable mouse support
 	if err := session.SetOption("mouse", "on"); err != nil {
 		// Non-fatal, continue without mouse support
-		fmt.Printf("Warning: could not enable mouse support: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Warning: could not enable mouse support: %v\n", err)
 	}
 
 	// Allow mouse scroll to pass through to TUI apps (glow, vim, less, etc.)
@@ -137,7 +140,7 @@ func (m *Manager) CreateSession(name string, workdir string, layout Layout) (*go
 	termCmd := exec.Command("tmux", "set-option", "-s", "terminal-overrides", ",*:smcup@:rmcup@")
 	if output, err := termCmd.CombinedOutput(); err != nil {
 		// Non-fatal, scroll may not work in TUI apps
-		fmt.Printf("Warning: could not set terminal-overrides: %v (output: %s)\n", err, string(output))
+		fmt.Fprintf(os.Stderr, "Warning: could not set terminal-overrides: %v (output: %s)\n", err, string(output))
 	}
 
 	// Get the first window
@@ -191,6 +194,15 @@ func (m *Manager) CreateSession(name string, workdir string, layout Layout) (*go
 		}
 	}
 
+	// Size panes according to the layout
+	if err := applyPaneSizes(name, layout); err != nil {
+		// Non-fatal, panes keep tmux's default even split
+		fmt.Fprintf(os.Stderr, "Warning: could not resize panes: %v\n", err)
+	}
+	if err := setLayoutName(name, layout.Name); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not record layout: %v\n", err)
+	}
+
 	// Get final list of panes
 	panes, err = window.ListPanes()
 	if err != nil {
@@ -231,6 +243,18 @@ func (m *Manager) KillSession(name string) error {
 	return fmt.Errorf("session %q not found", name)
 }
 
+// RenameSession renames an existing tmux session.
+func (m *Manager) RenameSession(name, newName string) error {
+	session, err := m.GetSession(name)
+	if err != nil {
+		return err
+	}
+	if err := session.Rename(newName); err != nil {
+		return fmt.Errorf("failed to rename session %q: %w", name, err)
+	}
+	return nil
+}
+
 // AttachSession attaches to an existing tmux session.
 func (m *Manager) AttachSession(name string) error {
 	sessions, err := m.tmux.ListSessions()
@@ -350,6 +374,51 @@ func isShellCommand(cmd string) bool {
 	return false
 }
 
+// commandProgram returns the program name of a shell command line.
+func commandProgram(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return ""
+	}
+	return filepath.Base(fields[0])
+}
+
+// applyPaneSizes resizes the panes of a session to the percentages in the layout.
+// Pane 0 sets the width of the left column; panes 1 and 2 share the right column
+// and split its height in proportion to their sizes. Zero sizes are left as-is.
+func applyPaneSizes(sessionName string, layout Layout) error {
+	if len(layout.Panes) < 2 {
+		return nil
+	}
+
+	if size := layout.Panes[0].Size; size > 0 && size < 100 {
+		if err := resizePane(sessionName, 0, "-x", size); err != nil {
+			return err
+		}
+	}
+
+	if len(layout.Panes) > 2 {
+		top, bottom := layout.Panes[1].Size, layout.Panes[2].Size
+		if top > 0 && bottom > 0 {
+			if err := resizePane(sessionName, 1, "-y", top*100/(top+bottom)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// resizePane sets a pane's width (-x) or height (-y) to a percentage of the window.
+func resizePane(sessionName string, paneIndex int, axis string, percent int) error {
+	target := fmt.Sprintf("%s:%d.%d", sessionName, 0, paneIndex)
+	cmd := exec.Command("tmux", "resize-pane", "-t", target, axis, fmt.Sprintf("%d%%", percent))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to resize pane %d: %w (output: %s)", paneIndex, err, string(output))
+	}
+	return nil
+}
+
 // layoutMatches checks if the current pane layout matches the target layout.
 // Returns true if no reconfiguration is needed.
 func (m *Manager) layoutMatches(sessionName string, layout Layout) bool {
@@ -363,6 +432,12 @@ func (m *Manager) layoutMatches(sessionName string, layout Layout) bool {
 		return false
 	}
 
+	// Layouts with the same pane count differ by name (e.g. plan vs review).
+	// Sessions created before layouts were recorded have no name to compare.
+	if current := layoutName(sessionName); current != "" && current != layout.Name {
+		return false
+	}
+
 	// Build a map of pane index -> command for easier lookup
 	paneByIndex := make(map[int]string)
 	for _, p := range panes {
@@ -385,8 +460,8 @@ func (m *Manager) layoutMatches(sessionName string, layout Layout) bool {
 				return false
 			}
 		case "plan":
-			// Plan pane should be running glow
-			if cmd != "glow" {
+			// Plan pane should be running its viewer (glow by default)
+			if cmd != commandProgram(spec.Command) {
 				return false
 			}
 		case "diff":
@@ -405,6 +480,28 @@ func (m *Manager) layoutMatches(sessionName string, layout Layout) bool {
 	return true
 }
 
+// layoutOption is the tmux user option that records the applied layout name.
+const layoutOption = "@planq_layout"
+
+// layoutName returns the name of the layout last applied to a session.
+func layoutName(sessionName string) string {
+	cmd := exec.Command("tmux", "show-options", "-v", "-t", sessionName, layoutOption)
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// setLayoutName records the name of the layout applied to a session.
+func setLayoutName(sessionName, name string) error {
+	cmd := exec.Command("tmux", "set-option", "-t", sessionName, layoutOption, name)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to set %s: %w (output: %s)", layoutOption, err, string(output))
+	}
+	return nil
+}
+
 // ReconfigureSession reconfigures the session to match the target layout.
 // This is idempotent - if the layout already matches, no changes are made.
 // If pane 0 has a running process (like claude), it will not be restarted.
@@ -487,6 +584,15 @@ func (m *Manager) ReconfigureSession(name string, workdir string, layout Layout)
 		}
 	}
 
+	// Size panes according to the layout
+	if err := applyPaneSizes(name, layout); err != nil {
+		// Non-fatal, panes keep tmux's default even split
+		fmt.Fprintf(os.Stderr, "Warning: could not resize panes: %v\n", err)
+	}
+	if err := setLayoutName(name, layout.Name); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not record layout: %v\n", err)
+	}
+
 	// Get final list of panes and send commands
 	panes, err = window.ListPanes()
 	if err != nil {
@@ -527,12 +633,31 @@ func (m *Manager) ReconfigureSession(name string, workdir string, layout Layout)
 	return true, nil
 }
 
+// RespawnPane restarts a pane with a fresh shell in workdir and runs command in it.
+// Any process running in the pane is killed.
+func (m *Manager) RespawnPane(sessionName string, paneIndex int, workdir, command string) error {
+	target := fmt.Sprintf("%s:%d.%d", sessionName, 0, paneIndex)
+	cmd := exec.Command("tmux", "respawn-pane", "-k", "-t", target, "-c", workdir)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to respawn pane %d: %w (output: %s)", paneIndex, err, string(output))
+	}
+
+	if command == "" {
+		return nil
+	}
+	cmd = exec.Command("tmux", "send-keys", "-t", target, command, "Enter")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to send command to pane %d: %w (output: %s)", paneIndex, err, string(output))
+	}
+	return nil
+}
+
 // BindModeToggle adds a keybinding (prefix + m) to toggle workspace mode.
 func (m *Manager) BindModeToggle(sessionName, workspaceName, worktreePath string) error {
 	// Bind 'm' key in this session to run planq mode toggle
 	// Quote the worktree path to handle spaces
 	cmd := exec.Command("tmux", "bind-key", "-t", sessionName, "m",
-		"run-shell", fmt.Sprintf("planq mode toggle --workspace '%s' --worktree '%s'", workspaceName, worktreePath))
+		"run-shell", fmt.Sprintf("planq mode toggle --trigger keybinding --workspace '%s' --worktree '%s'", workspaceName, worktreePath))
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to bind mode toggle key: %w (output: %s)", err, string(output))
 	}
@@ -550,7 +675,13 @@ func (m *Manager) SetEnvironment(sessionName, key, value string) error {
 
 // ConfigureStatusBar sets up the tmux status bar with workspace info and help hints.
 // This should be called during session creation and when mode changes.
-func (m *Manager) ConfigureStatusBar(sessionName, workspaceName, mode string) error {
+// color is the background of the workspace/mode segment (default: blue).
+// The plan checklist progress is refreshed by tmux every status-interval.
+func (m *Manager) ConfigureStatusBar(sessionName, workspaceName, worktreePath, mode, color string) error {
+	if color == "" {
+		color = "#89b4fa"
+	}
+
 	// Extract just the workspace name if it has the planq- prefix
 	displayName := workspaceName
 
@@ -567,8 +698,11 @@ func (m *Manager) ConfigureStatusBar(sessionName, workspaceName, mode string) er
 		countDisplay = fmt.Sprintf(" (%d/%d)", position, total)
 	}
 
-	// Status bar left: workspace name, mode, and count
-	statusLeft := fmt.Sprintf(" [planq] %s │ %s%s ", displayName, displayMode, countDisplay)
+	// Plan progress is read from the plan file each time tmux redraws the status bar
+	progress := fmt.Sprintf("#(planq plan progress --status --workspace '%s' --worktree '%s')", workspaceName, worktreePath)
+
+	// Status bar left: workspace name, mode, count and plan progress
+	statusLeft := fmt.Sprintf(" [planq] %s │ %s%s%s ", displayName, displayMode, countDisplay, progress)
 
 	// Status bar right: keybinding hints (include workspace switching)
 	statusRight := " ^B w: switch │ ^B m: mode │ ^B ?: help "
@@ -581,8 +715,8 @@ func (m *Manager) ConfigureStatusBar(sessionName, workspaceName, mode string) er
 		{"status", "on"},
 		{"status-style", "bg=#1e1e2e,fg=#cdd6f4"},
 		{"status-left", statusLeft},
-		{"status-left-style", "bg=#89b4fa,fg=#1e1e2e,bold"},
-		{"status-left-length", "50"},
+		{"status-left-style", fmt.Sprintf("bg=%s,fg=#1e1e2e,bold", color)},
+		{"status-left-length", "80"},
 		{"status-right", statusRight},
 		{"status-right-style", "bg=#313244,fg=#a6adc8"},
 		{"status-right-length", "50"},
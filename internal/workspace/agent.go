@@ -0,0 +1,230 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"planq.dev/planq/internal/deps"
+)
+
+// DefaultAgentName is the agent used when none is configured.
+const DefaultAgentName = "claude"
+
+// LaunchOptions describe how an agent should be started.
+type LaunchOptions struct {
+	// Binary overrides the agent executable (default: the agent's own binary).
+	Binary string
+	// SystemPrompt holds the mode instructions for the agent.
+	SystemPrompt string
+	// InitialPrompt is sent as the agent's first message, if set.
+	InitialPrompt string
+}
+
+// Agent is a terminal coding agent that planq can run in the agent pane.
+type Agent interface {
+	// Name returns the name the agent is registered under.
+	Name() string
+	// LaunchCommand returns the shell command that starts the agent with the mode prompt.
+	LaunchCommand(w *Workspace, opts LaunchOptions) (string, error)
+	// Dependencies returns the external tools the agent needs.
+	Dependencies() []deps.Dependency
+	// Install writes the agent's skill and settings files into the workspace.
+	Install(w *Workspace) error
+	// Outdated reports whether the installed skill files are missing or differ
+	// from the ones this planq would write.
+	Outdated(w *Workspace) (bool, error)
+}
+
+// agents holds the registered agent backends by name.
+var agents = map[string]Agent{
+	"claude": claudeAgent{},
+	"aider":  aiderAgent{},
+	"codex":  codexAgent{},
+	"gemini": geminiAgent{},
+}
+
+// LookupAgent returns the agent registered under name.
+func LookupAgent(name string) (Agent, error) {
+	agent, ok := agents[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown agent %q (available: %v)", name, AgentNames())
+	}
+	return agent, nil
+}
+
+// AgentNames returns the names of all registered agents, sorted.
+func AgentNames() []string {
+	names := make([]string, 0, len(agents))
+	for name := range agents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Agent returns the agent backend for this workspace.
+func (w *Workspace) Agent() (Agent, error) {
+	name := w.AgentName
+	if name == "" {
+		name = DefaultAgentName
+	}
+	return LookupAgent(name)
+}
+
+// SkillFile returns the path to the planq-mode skill for Claude.
+func (w *Workspace) SkillFile() string {
+	return filepath.Join(w.ClaudeCommandsDir(), "planq-mode.md")
+}
+
+// InstallAgent writes the workspace agent's skill and settings files.
+func (w *Workspace) InstallAgent() error {
+	agent, err := w.Agent()
+	if err != nil {
+		return err
+	}
+	if err := agent.Install(w); err != nil {
+		return fmt.Errorf("failed to install %s files: %w", agent.Name(), err)
+	}
+	return nil
+}
+
+// openingMessage combines the system and initial prompts for agents that
+// only take a single opening message.
+func (o LaunchOptions) openingMessage() string {
+	if o.InitialPrompt == "" {
+		return o.SystemPrompt
+	}
+	return o.SystemPrompt + "\n\n" + o.InitialPrompt
+}
+
+// binaryOr returns the override binary if set, otherwise the default.
+func (o LaunchOptions) binaryOr(def string) string {
+	if o.Binary != "" {
+		return o.Binary
+	}
+	return def
+}
+
+// claudeAgent runs Claude Code, injecting the prompt with --append-system-prompt.
+type claudeAgent struct{}
+
+func (claudeAgent) Name() string { return "claude" }
+
+func (claudeAgent) LaunchCommand(w *Workspace, opts LaunchOptions) (string, error) {
+	cmd := fmt.Sprintf("%s --append-system-prompt %q", opts.binaryOr("claude"), opts.SystemPrompt)
+	if opts.InitialPrompt != "" {
+		cmd += fmt.Sprintf(" %q", opts.InitialPrompt)
+	}
+	return cmd, nil
+}
+
+func (claudeAgent) Dependencies() []deps.Dependency {
+	return []deps.Dependency{{
+		Name:        "claude",
+		Required:    true,
+		Description: "Claude AI assistant CLI",
+		InstallHint: "npm install -g @anthropic-ai/claude-code",
+	}}
+}
+
+// Install writes the planq-mode skill and points Claude's plans directory at .planq/agent/plans.
+func (claudeAgent) Install(w *Workspace) error {
+	if err := os.MkdirAll(w.ClaudeCommandsDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", w.ClaudeCommandsDir(), err)
+	}
+
+	skillFile := w.SkillFile()
+	if err := os.WriteFile(skillFile, []byte(planqModeSkill), 0644); err != nil {
+		return fmt.Errorf("failed to create skill file %s: %w", skillFile, err)
+	}
+
+	return w.ConfigureClaudeSettings()
+}
+
+func (claudeAgent) Outdated(w *Workspace) (bool, error) {
+	content, err := os.ReadFile(w.SkillFile())
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read skill file: %w", err)
+	}
+	return string(content) != planqModeSkill, nil
+}
+
+// aiderAgent runs aider. Aider has no system prompt flag, so the mode prompt
+// (and any initial prompt) is written to .planq/agent/prompt.md and loaded as a
+// read-only file.
+type aiderAgent struct{}
+
+func (aiderAgent) Name() string { return "aider" }
+
+func (aiderAgent) LaunchCommand(w *Workspace, opts LaunchOptions) (string, error) {
+	promptFile := filepath.Join(w.AgentDir(), "prompt.md")
+	if err := os.MkdirAll(w.AgentDir(), 0755); err != nil {
+		return "", fmt.Errorf("failed to create agent directory: %w", err)
+	}
+	if err := os.WriteFile(promptFile, []byte(opts.openingMessage()+"\n"), 0644); err != nil {
+		return "", fmt.Errorf("failed to write prompt file: %w", err)
+	}
+	return fmt.Sprintf("%s --read %q", opts.binaryOr("aider"), promptFile), nil
+}
+
+func (aiderAgent) Dependencies() []deps.Dependency {
+	return []deps.Dependency{{
+		Name:        "aider",
+		Required:    true,
+		Description: "aider AI pair programming CLI",
+		InstallHint: "python -m pip install aider-install && aider-install",
+	}}
+}
+
+func (aiderAgent) Install(w *Workspace) error { return nil }
+
+func (aiderAgent) Outdated(w *Workspace) (bool, error) { return false, nil }
+
+// codexAgent runs the OpenAI Codex CLI, passing the mode prompt as the opening message.
+type codexAgent struct{}
+
+func (codexAgent) Name() string { return "codex" }
+
+func (codexAgent) LaunchCommand(w *Workspace, opts LaunchOptions) (string, error) {
+	return fmt.Sprintf("%s %q", opts.binaryOr("codex"), opts.openingMessage()), nil
+}
+
+func (codexAgent) Dependencies() []deps.Dependency {
+	return []deps.Dependency{{
+		Name:        "codex",
+		Required:    true,
+		Description: "OpenAI Codex CLI",
+		InstallHint: "npm install -g @openai/codex",
+	}}
+}
+
+func (codexAgent) Install(w *Workspace) error { return nil }
+
+func (codexAgent) Outdated(w *Workspace) (bool, error) { return false, nil }
+
+// geminiAgent runs gemini-cli, passing the mode prompt with --prompt-interactive.
+type geminiAgent struct{}
+
+func (geminiAgent) Name() string { return "gemini" }
+
+func (geminiAgent) LaunchCommand(w *Workspace, opts LaunchOptions) (string, error) {
+	return fmt.Sprintf("%s --prompt-interactive %q", opts.binaryOr("gemini"), opts.openingMessage()), nil
+}
+
+func (geminiAgent) Dependencies() []deps.Dependency {
+	return []deps.Dependency{{
+		Name:        "gemini",
+		Required:    true,
+		Description: "Google Gemini CLI",
+		InstallHint: "npm install -g @google/gemini-cli",
+	}}
+}
+
+func (geminiAgent) Install(w *Workspace) error { return nil }
+
+func (geminiAgent) Outdated(w *Workspace) (bool, error) { return false, nil }
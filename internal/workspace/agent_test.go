@@ -0,0 +1,140 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLookupAgent(t *testing.T) {
+	for _, name := range AgentNames() {
+		agent, err := LookupAgent(name)
+		if err != nil {
+			t.Fatalf("LookupAgent(%q) failed: %v", name, err)
+		}
+		if agent.Name() != name {
+			t.Errorf("LookupAgent(%q).Name() = %q", name, agent.Name())
+		}
+		if len(agent.Dependencies()) == 0 {
+			t.Errorf("agent %q declares no dependencies", name)
+		}
+	}
+
+	if _, err := LookupAgent("nope"); err == nil {
+		t.Error("LookupAgent(\"nope\") succeeded, want error")
+	}
+}
+
+func TestAgentCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		agent  string
+		binary string
+		prefix string
+	}{
+		{agent: "", prefix: "claude --append-system-prompt "},
+		{agent: "claude", binary: "/opt/claude", prefix: "/opt/claude --append-system-prompt "},
+		{agent: "aider", prefix: "aider --read "},
+		{agent: "codex", prefix: "codex "},
+		{agent: "gemini", prefix: "gemini --prompt-interactive "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.agent, func(t *testing.T) {
+			ws := &Workspace{
+				Name:         "test-workspace",
+				WorktreePath: tmpDir,
+				AgentName:    tt.agent,
+				AgentBinary:  tt.binary,
+			}
+
+			cmd, err := ws.AgentCommand()
+			if err != nil {
+				t.Fatalf("AgentCommand() failed: %v", err)
+			}
+			if !strings.HasPrefix(cmd, tt.prefix) {
+				t.Errorf("AgentCommand() = %q, want prefix %q", cmd, tt.prefix)
+			}
+		})
+	}
+
+	// aider reads its prompt from a file
+	content, err := os.ReadFile(filepath.Join(tmpDir, ".planq", "agent", "prompt.md"))
+	if err != nil {
+		t.Fatalf("Failed to read aider prompt file: %v", err)
+	}
+	if !strings.Contains(string(content), "planning mode") {
+		t.Error("aider prompt file missing plan mode prompt")
+	}
+}
+
+func TestAgentCommand_InitialPrompt(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	for _, name := range AgentNames() {
+		ws := &Workspace{
+			Name:          "test-workspace",
+			WorktreePath:  tmpDir,
+			AgentName:     name,
+			InitialPrompt: "Work on queued item #4",
+		}
+
+		cmd, err := ws.AgentCommand()
+		if err != nil {
+			t.Fatalf("AgentCommand() for %s failed: %v", name, err)
+		}
+		if name == "aider" {
+			content, err := os.ReadFile(filepath.Join(tmpDir, ".planq", "agent", "prompt.md"))
+			if err != nil {
+				t.Fatalf("Failed to read aider prompt file: %v", err)
+			}
+			cmd = string(content)
+		}
+		if !strings.Contains(cmd, "Work on queued item #4") {
+			t.Errorf("%s command does not include the initial prompt: %q", name, cmd)
+		}
+	}
+}
+
+func TestInstallAgent_Claude(t *testing.T) {
+	tmpDir := t.TempDir()
+	ws := &Workspace{Name: "test-workspace", WorktreePath: tmpDir}
+
+	if err := ws.InstallAgent(); err != nil {
+		t.Fatalf("InstallAgent() failed: %v", err)
+	}
+
+	skill := filepath.Join(tmpDir, ".claude", "commands", "planq-mode.md")
+	if _, err := os.Stat(skill); err != nil {
+		t.Errorf("planq-mode skill not installed: %v", err)
+	}
+}
+
+func TestAgentOutdated_Claude(t *testing.T) {
+	tmpDir := t.TempDir()
+	ws := &Workspace{Name: "test-workspace", WorktreePath: tmpDir}
+	agent, err := ws.Agent()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if outdated, err := agent.Outdated(ws); err != nil || !outdated {
+		t.Errorf("Outdated() before install = %v, %v; want true", outdated, err)
+	}
+
+	if err := ws.InstallAgent(); err != nil {
+		t.Fatalf("InstallAgent() failed: %v", err)
+	}
+	if outdated, err := agent.Outdated(ws); err != nil || outdated {
+		t.Errorf("Outdated() after install = %v, %v; want false", outdated, err)
+	}
+
+	if err := os.WriteFile(ws.SkillFile(), []byte("old skill\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if outdated, err := agent.Outdated(ws); err != nil || !outdated {
+		t.Errorf("Outdated() with edited skill = %v, %v; want true", outdated, err)
+	}
+}
@@ -0,0 +1,142 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"planq.dev/planq/internal/statefile"
+)
+
+// ApprovalStatus describes whether the current plan is approved.
+type ApprovalStatus string
+
+const (
+	// ApprovalNone means the plan has never been approved.
+	ApprovalNone ApprovalStatus = "unapproved"
+	// ApprovalValid means the plan is approved and unchanged since.
+	ApprovalValid ApprovalStatus = "approved"
+	// ApprovalStale means the plan changed after it was approved.
+	ApprovalStale ApprovalStatus = "stale"
+)
+
+// ApprovalSource records where the approver's name came from.
+type ApprovalSource string
+
+const (
+	// ApprovalSourceFlag means the name was given with --by.
+	ApprovalSourceFlag ApprovalSource = "flag"
+	// ApprovalSourceGit means the name is git user.name.
+	ApprovalSourceGit ApprovalSource = "git"
+	// ApprovalSourceEnv means the name is $USER.
+	ApprovalSourceEnv ApprovalSource = "env"
+)
+
+// Approval records who approved the plan and the plan contents at that time.
+type Approval struct {
+	ApprovedBy string         `json:"approved_by"`
+	Source     ApprovalSource `json:"source,omitempty"`
+	ApprovedAt time.Time      `json:"approved_at"`
+	PlanHash   string         `json:"plan_hash"`
+}
+
+// ApprovalFile returns the path to the plan approval file.
+func (w *Workspace) ApprovalFile() string {
+	return filepath.Join(w.PlanqDir(), "approval.json")
+}
+
+// GetApproval returns the recorded plan approval, or nil if the plan was never approved.
+func (w *Workspace) GetApproval() (*Approval, error) {
+	data, err := os.ReadFile(w.ApprovalFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read approval file: %w", err)
+	}
+
+	var approval Approval
+	if err := json.Unmarshal(data, &approval); err != nil {
+		return nil, fmt.Errorf("failed to parse approval file: %w", err)
+	}
+
+	return &approval, nil
+}
+
+// ApprovePlan records approval of the current plan contents by the given person
+// and snapshots the approved plan.
+func (w *Workspace) ApprovePlan(by string, source ApprovalSource) (*Approval, error) {
+	if by == "" {
+		return nil, fmt.Errorf("approver is required")
+	}
+
+	planHash, err := w.PlanHash()
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(w.PlanFile()); err != nil || info.Size() == 0 {
+		return nil, fmt.Errorf("plan file %s is empty", w.PlanFile())
+	}
+
+	approval := Approval{
+		ApprovedBy: by,
+		Source:     source,
+		ApprovedAt: time.Now(),
+		PlanHash:   planHash,
+	}
+
+	data, err := json.MarshalIndent(approval, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal approval: %w", err)
+	}
+
+	if err := statefile.WriteFile(w.ApprovalFile(), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write approval file: %w", err)
+	}
+
+	if _, err := w.SnapshotPlan(SnapshotApprove); err != nil {
+		return nil, err
+	}
+
+	return &approval, nil
+}
+
+// PlanApprovalStatus compares the recorded approval against the current plan.
+func (w *Workspace) PlanApprovalStatus() (ApprovalStatus, error) {
+	approval, err := w.GetApproval()
+	if err != nil {
+		return "", err
+	}
+	if approval == nil {
+		return ApprovalNone, nil
+	}
+
+	planHash, err := w.PlanHash()
+	if err != nil {
+		return "", err
+	}
+	if planHash != approval.PlanHash {
+		return ApprovalStale, nil
+	}
+
+	return ApprovalValid, nil
+}
+
+// checkApproval returns an error unless the current plan is approved.
+func (w *Workspace) checkApproval() error {
+	status, err := w.PlanApprovalStatus()
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case ApprovalNone:
+		return fmt.Errorf("plan has not been approved: run 'planq plan approve %s' or use --force", w.Name)
+	case ApprovalStale:
+		return fmt.Errorf("plan changed after it was approved: run 'planq plan approve %s' again or use --force", w.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package workspace
+
+import (
+	"os"
+	"testing"
+)
+
+func TestApprovalGate(t *testing.T) {
+	ws := &Workspace{Name: "test-workspace", WorktreePath: t.TempDir()}
+	if err := os.MkdirAll(ws.PlanqDir(), 0755); err != nil {
+		t.Fatalf("Failed to create .planq: %v", err)
+	}
+	if err := os.WriteFile(ws.PlanFile(), []byte{}, 0644); err != nil {
+		t.Fatalf("Failed to write plan: %v", err)
+	}
+
+	if _, err := ws.ApprovePlan("tester", ApprovalSourceFlag); err == nil {
+		t.Error("ApprovePlan() accepted an empty plan")
+	}
+	if err := ws.SetMode(ModeExecute, SwitchOptions{}); err == nil {
+		t.Fatal("SetMode(execute) succeeded without approval")
+	}
+
+	if err := os.WriteFile(ws.PlanFile(), []byte("# Plan\n"), 0644); err != nil {
+		t.Fatalf("Failed to write plan: %v", err)
+	}
+	if _, err := ws.ApprovePlan("tester", ApprovalSourceFlag); err != nil {
+		t.Fatalf("ApprovePlan() failed: %v", err)
+	}
+	if approval, err := ws.GetApproval(); err != nil || approval.ApprovedBy != "tester" || approval.Source != ApprovalSourceFlag {
+		t.Errorf("GetApproval() = %+v, %v", approval, err)
+	}
+	if status, _ := ws.PlanApprovalStatus(); status != ApprovalValid {
+		t.Errorf("PlanApprovalStatus() = %q, want %q", status, ApprovalValid)
+	}
+
+	// Editing the plan invalidates the approval
+	if err := os.WriteFile(ws.PlanFile(), []byte("# Plan\n\n- more\n"), 0644); err != nil {
+		t.Fatalf("Failed to write plan: %v", err)
+	}
+	if status, _ := ws.PlanApprovalStatus(); status != ApprovalStale {
+		t.Errorf("PlanApprovalStatus() = %q, want %q", status, ApprovalStale)
+	}
+	if err := ws.SetMode(ModeExecute, SwitchOptions{}); err == nil {
+		t.Fatal("SetMode(execute) succeeded with a stale approval")
+	}
+
+	if err := ws.SetMode(ModeExecute, SwitchOptions{Force: true}); err != nil {
+		t.Fatalf("SetMode(execute, force) failed: %v", err)
+	}
+}
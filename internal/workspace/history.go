@@ -0,0 +1,127 @@
+package workspace
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"planq.dev/planq/internal/statefile"
+)
+
+// Trigger identifies what initiated a mode switch.
+type Trigger string
+
+const (
+	// TriggerCLI is a switch run from the planq CLI.
+	TriggerCLI Trigger = "cli"
+	// TriggerKeybinding is a switch from the tmux mode toggle key.
+	TriggerKeybinding Trigger = "keybinding"
+	// TriggerSkill is a switch run by the agent through the planq-mode skill.
+	TriggerSkill Trigger = "skill"
+	// TriggerMCP is a switch made through the planq MCP server.
+	TriggerMCP Trigger = "mcp"
+)
+
+// Triggers lists all valid triggers.
+var Triggers = []Trigger{TriggerCLI, TriggerKeybinding, TriggerSkill, TriggerMCP}
+
+// ParseTrigger validates a trigger name.
+func ParseTrigger(s string) (Trigger, error) {
+	for _, t := range Triggers {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("invalid trigger %q (expected one of %v)", s, Triggers)
+}
+
+// Transition is a single entry in the mode history.
+type Transition struct {
+	From     Mode      `json:"from"`
+	To       Mode      `json:"to"`
+	At       time.Time `json:"at"`
+	Trigger  Trigger   `json:"trigger"`
+	PlanHash string    `json:"plan_hash,omitempty"`
+}
+
+// ModeHistoryFile returns the path to the append-only mode history log.
+func (w *Workspace) ModeHistoryFile() string {
+	return filepath.Join(w.PlanqDir(), "mode-history.jsonl")
+}
+
+// PlanHash returns the SHA-256 of the plan file contents, or "" if there is no plan file.
+func (w *Workspace) PlanHash() (string, error) {
+	data, err := os.ReadFile(w.PlanFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read plan file: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// recordTransition appends a transition to the mode history.
+func (w *Workspace) recordTransition(from, to Mode, trigger Trigger) error {
+	if trigger == "" {
+		trigger = TriggerCLI
+	}
+
+	planHash, err := w.PlanHash()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(Transition{
+		From:     from,
+		To:       to,
+		At:       time.Now(),
+		Trigger:  trigger,
+		PlanHash: planHash,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal transition: %w", err)
+	}
+
+	if err := statefile.Append(w.ModeHistoryFile(), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write mode history: %w", err)
+	}
+	return nil
+}
+
+// ModeHistory returns all recorded transitions, oldest first.
+func (w *Workspace) ModeHistory() ([]Transition, error) {
+	f, err := os.Open(w.ModeHistoryFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open mode history: %w", err)
+	}
+	defer f.Close()
+
+	var history []Transition
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var t Transition
+		if err := json.Unmarshal(line, &t); err != nil {
+			return nil, fmt.Errorf("failed to parse mode history: %w", err)
+		}
+		history = append(history, t)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mode history: %w", err)
+	}
+
+	return history, nil
+}
@@ -0,0 +1,107 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"planq.dev/planq/internal/statefile"
+)
+
+// Metadata describes a workspace. It is written to .planq/workspace.json at
+// create time and is the source of truth for the workspace's identity.
+type Metadata struct {
+	Name string `json:"name"`
+	// Repo is the repository root the workspace was created from.
+	Repo string `json:"repo"`
+	// WorktreePath is the directory the workspace lives in.
+	WorktreePath string `json:"worktree_path"`
+	// Branch is the branch checked out in the worktree at create time.
+	Branch string `json:"branch,omitempty"`
+	// BaseBranch is the branch the workspace was created from.
+	BaseBranch string `json:"base_branch,omitempty"`
+	Scope      string `json:"scope,omitempty"`
+	// Agent is the agent backend chosen for the workspace (empty for the config default).
+	Agent string `json:"agent,omitempty"`
+	// AgentCommand overrides the agent pane command (from --agent-cmd).
+	AgentCommand string `json:"agent_command,omitempty"`
+	// Layout is the name of the layout the session was created with.
+	Layout string `json:"layout,omitempty"`
+	// Main is set when the workspace uses the repository's main worktree.
+	Main bool `json:"main,omitempty"`
+	// Adopted is set when the worktree existed before planq adopt took it
+	// over. planq remove leaves such worktrees in place.
+	Adopted bool `json:"adopted,omitempty"`
+	// Parent is the workspace this one was created from, if any.
+	Parent string `json:"parent,omitempty"`
+	// QueueItem is the queue item the workspace was started from, if any.
+	QueueItem int `json:"queue_item,omitempty"`
+	// QueueRoot is the project root whose queue holds QueueItem.
+	QueueRoot string    `json:"queue_root,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MetadataFile returns the path to the workspace metadata file.
+func (w *Workspace) MetadataFile() string {
+	return filepath.Join(w.PlanqDir(), "workspace.json")
+}
+
+// ReadMetadata reads the workspace metadata in worktreePath.
+// Returns nil if the directory has no metadata file.
+func ReadMetadata(worktreePath string) (*Metadata, error) {
+	w := &Workspace{WorktreePath: worktreePath}
+	data, err := os.ReadFile(w.MetadataFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read workspace metadata: %w", err)
+	}
+
+	var meta Metadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse workspace metadata: %w", err)
+	}
+
+	return &meta, nil
+}
+
+// WriteMetadata writes the workspace metadata file.
+func (w *Workspace) WriteMetadata(meta *Metadata) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal workspace metadata: %w", err)
+	}
+
+	if err := os.MkdirAll(w.PlanqDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create planq directory: %w", err)
+	}
+
+	if err := statefile.WriteFile(w.MetadataFile(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write workspace metadata: %w", err)
+	}
+
+	return nil
+}
+
+// FindMetadata walks up from dir looking for a workspace metadata file.
+// Returns nil if dir is not inside a workspace.
+func FindMetadata(dir string) (*Metadata, string, error) {
+	for {
+		meta, err := ReadMetadata(dir)
+		if err != nil {
+			return nil, "", err
+		}
+		if meta != nil {
+			return meta, dir, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, "", nil
+		}
+		dir = parent
+	}
+}
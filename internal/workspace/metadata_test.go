@@ -0,0 +1,46 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMetadata(t *testing.T) {
+	tmpDir := t.TempDir()
+	ws := &Workspace{Name: "test-workspace", WorktreePath: tmpDir}
+
+	if meta, err := ReadMetadata(tmpDir); err != nil || meta != nil {
+		t.Fatalf("ReadMetadata() on a fresh dir = %v, %v", meta, err)
+	}
+
+	want := &Metadata{
+		Name:         ws.Name,
+		Repo:         "/repo",
+		WorktreePath: tmpDir,
+		BaseBranch:   "main",
+		Agent:        "aider",
+		Parent:       "parent-workspace",
+		CreatedAt:    time.Now().Truncate(time.Second),
+	}
+	if err := ws.WriteMetadata(want); err != nil {
+		t.Fatalf("WriteMetadata() failed: %v", err)
+	}
+
+	nested := filepath.Join(tmpDir, "src", "pkg")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("Failed to create nested dir: %v", err)
+	}
+
+	got, root, err := FindMetadata(nested)
+	if err != nil {
+		t.Fatalf("FindMetadata() failed: %v", err)
+	}
+	if root != tmpDir {
+		t.Errorf("FindMetadata() root = %q, want %q", root, tmpDir)
+	}
+	if got.Name != want.Name || got.Agent != want.Agent || got.Parent != want.Parent || !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("FindMetadata() = %+v, want %+v", got, want)
+	}
+}
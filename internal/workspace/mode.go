@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"planq.dev/planq/internal/statefile"
 )
 
 // Mode represents the workspace mode.
@@ -47,8 +49,66 @@ func (w *Workspace) GetMode() (Mode, error) {
 	return state.Mode, nil
 }
 
-// SetMode updates the workspace mode.
-func (w *Workspace) SetMode(mode Mode) error {
+// SwitchOptions describe a mode switch.
+type SwitchOptions struct {
+	// Trigger records what initiated the switch (default: cli).
+	Trigger Trigger
+	// Force skips the plan approval check when entering execute mode.
+	Force bool
+}
+
+// SetMode updates the workspace mode and records the transition in the mode history.
+// The mode must be registered and reachable from the current mode.
+// Entering execute mode requires an approved, unchanged plan unless opts.Force is set,
+// and snapshots the plan.
+func (w *Workspace) SetMode(mode Mode, opts SwitchOptions) error {
+	lock, err := statefile.Acquire(w.ModeFile())
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	return w.setMode(mode, opts)
+}
+
+// setMode switches modes; the caller must hold the mode file lock.
+func (w *Workspace) setMode(mode Mode, opts SwitchOptions) error {
+	if _, err := LookupMode(mode); err != nil {
+		return err
+	}
+
+	current, err := w.GetMode()
+	if err != nil {
+		return err
+	}
+	currentSpec, err := LookupMode(current)
+	if err == nil && !currentSpec.CanTransition(mode) {
+		return fmt.Errorf("cannot switch from %s mode to %s mode (allowed: %v)", current, mode, currentSpec.Transitions)
+	}
+
+	if mode == ModeExecute && current != ModeExecute {
+		if !opts.Force {
+			if err := w.checkApproval(); err != nil {
+				return err
+			}
+		}
+		if _, err := w.SnapshotPlan(SnapshotExecute); err != nil {
+			return err
+		}
+	}
+
+	if err := w.writeMode(mode); err != nil {
+		return err
+	}
+
+	if current == mode {
+		return nil
+	}
+	return w.recordTransition(current, mode, opts.Trigger)
+}
+
+// writeMode records the mode without checking transitions.
+func (w *Workspace) writeMode(mode Mode) error {
 	state := ModeState{
 		Mode:       mode,
 		SwitchedAt: time.Now(),
@@ -59,28 +119,33 @@ func (w *Workspace) SetMode(mode Mode) error {
 		return fmt.Errorf("failed to marshal mode state: %w", err)
 	}
 
-	if err := os.WriteFile(w.ModeFile(), data, 0644); err != nil {
+	if err := statefile.WriteFile(w.ModeFile(), data, 0644); err != nil {
 		return fmt.Errorf("failed to write mode file: %w", err)
 	}
 
 	return nil
 }
 
-// ToggleMode switches between plan and execute modes.
-func (w *Workspace) ToggleMode() (Mode, error) {
+// ToggleMode switches to the first transition of the current mode
+// (plan and execute toggle between each other).
+func (w *Workspace) ToggleMode(opts SwitchOptions) (Mode, error) {
+	lock, err := statefile.Acquire(w.ModeFile())
+	if err != nil {
+		return "", err
+	}
+	defer lock.Release()
+
 	current, err := w.GetMode()
 	if err != nil {
 		return "", err
 	}
 
-	var newMode Mode
-	if current == ModePlan {
-		newMode = ModeExecute
-	} else {
-		newMode = ModePlan
+	newMode := ModePlan
+	if spec, err := LookupMode(current); err == nil && len(spec.Transitions) > 0 {
+		newMode = spec.Transitions[0]
 	}
 
-	if err := w.SetMode(newMode); err != nil {
+	if err := w.setMode(newMode, opts); err != nil {
 		return "", err
 	}
 
@@ -0,0 +1,141 @@
+package workspace
+
+import (
+	"fmt"
+
+	"planq.dev/planq/internal/tmux"
+)
+
+const (
+	// ModeReview is the review mode where the agent reviews changes against the plan.
+	ModeReview Mode = "review"
+	// ModeTest is the test mode where the agent writes and runs tests.
+	ModeTest Mode = "test"
+)
+
+// ModeSpec declares how a mode behaves.
+type ModeSpec struct {
+	Name        Mode
+	Description string
+	// Prompt returns the agent instructions for the mode.
+	Prompt func(w *Workspace) string
+	// Layout returns the default tmux layout for the mode.
+	Layout func(w *Workspace, agentCmd string) tmux.Layout
+	// PaneTitles are the pane border titles for the default layout.
+	PaneTitles []string
+	// StatusColor is the status bar background color for the mode.
+	StatusColor string
+	// Transitions lists the modes this mode may switch to.
+	// The first entry is the target of ToggleMode.
+	Transitions []Mode
+}
+
+// CanTransition reports whether the mode may switch to target.
+func (s ModeSpec) CanTransition(target Mode) bool {
+	if target == s.Name {
+		return true
+	}
+	for _, m := range s.Transitions {
+		if m == target {
+			return true
+		}
+	}
+	return false
+}
+
+// modes holds registered modes in registration order.
+var modes []ModeSpec
+
+// RegisterMode adds a mode to the registry.
+func RegisterMode(spec ModeSpec) error {
+	if spec.Name == "" {
+		return fmt.Errorf("mode name is required")
+	}
+	if spec.Prompt == nil || spec.Layout == nil {
+		return fmt.Errorf("mode %q must define a prompt and a layout", spec.Name)
+	}
+	if _, err := LookupMode(spec.Name); err == nil {
+		return fmt.Errorf("mode %q is already registered", spec.Name)
+	}
+	modes = append(modes, spec)
+	return nil
+}
+
+// LookupMode returns the spec for a registered mode.
+func LookupMode(mode Mode) (ModeSpec, error) {
+	for _, spec := range modes {
+		if spec.Name == mode {
+			return spec, nil
+		}
+	}
+	return ModeSpec{}, fmt.Errorf("unknown mode %q (available: %v)", mode, ModeNames())
+}
+
+// Modes returns all registered modes in registration order.
+func Modes() []ModeSpec {
+	return append([]ModeSpec(nil), modes...)
+}
+
+// ModeNames returns the names of all registered modes in registration order.
+func ModeNames() []string {
+	names := make([]string, 0, len(modes))
+	for _, spec := range modes {
+		names = append(names, string(spec.Name))
+	}
+	return names
+}
+
+func init() {
+	builtins := []ModeSpec{
+		{
+			Name:        ModePlan,
+			Description: "Agent writes the plan file, no code changes",
+			Prompt:      (*Workspace).planPrompt,
+			Layout: func(w *Workspace, agentCmd string) tmux.Layout {
+				return tmux.PlanLayout(agentCmd, w.PlanFile())
+			},
+			PaneTitles:  []string{"Agent", "Plan", "Terminal"},
+			StatusColor: "#89b4fa", // blue
+			Transitions: []Mode{ModeExecute, ModeReview, ModeTest},
+		},
+		{
+			Name:        ModeExecute,
+			Description: "Agent implements the plan",
+			Prompt:      (*Workspace).executePrompt,
+			Layout: func(w *Workspace, agentCmd string) tmux.Layout {
+				return tmux.ExecuteLayout(agentCmd)
+			},
+			PaneTitles:  []string{"Agent", "Diff"},
+			StatusColor: "#a6e3a1", // green
+			Transitions: []Mode{ModePlan, ModeReview, ModeTest},
+		},
+		{
+			Name:        ModeReview,
+			Description: "Agent reviews the changes against the plan",
+			Prompt:      (*Workspace).reviewPrompt,
+			Layout: func(w *Workspace, agentCmd string) tmux.Layout {
+				return tmux.ReviewLayout(agentCmd)
+			},
+			PaneTitles:  []string{"Reviewer", "Diff", "Terminal"},
+			StatusColor: "#f9e2af", // yellow
+			Transitions: []Mode{ModePlan, ModeExecute},
+		},
+		{
+			Name:        ModeTest,
+			Description: "Agent writes and runs tests for the changes",
+			Prompt:      (*Workspace).testPrompt,
+			Layout: func(w *Workspace, agentCmd string) tmux.Layout {
+				return tmux.TestLayout(agentCmd)
+			},
+			PaneTitles:  []string{"Agent", "Tests"},
+			StatusColor: "#cba6f7", // mauve
+			Transitions: []Mode{ModeExecute, ModePlan, ModeReview},
+		},
+	}
+
+	for _, spec := range builtins {
+		if err := RegisterMode(spec); err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package workspace
+
+import (
+	"os"
+	"testing"
+
+	"planq.dev/planq/internal/tmux"
+)
+
+// newModeWorkspace returns a workspace with an initialized .planq directory and an approved plan.
+func newModeWorkspace(t *testing.T) *Workspace {
+	t.Helper()
+	ws := &Workspace{Name: "test-workspace", WorktreePath: t.TempDir()}
+	if err := os.MkdirAll(ws.PlanqDir(), 0755); err != nil {
+		t.Fatalf("Failed to create .planq: %v", err)
+	}
+	if err := os.WriteFile(ws.PlanFile(), []byte("# Plan\n"), 0644); err != nil {
+		t.Fatalf("Failed to write plan: %v", err)
+	}
+	if _, err := ws.ApprovePlan("tester", ApprovalSourceFlag); err != nil {
+		t.Fatalf("ApprovePlan() failed: %v", err)
+	}
+	return ws
+}
+
+func TestBuiltinModes(t *testing.T) {
+	ws := &Workspace{Name: "test-workspace", WorktreePath: "/path/to/worktree"}
+
+	for _, spec := range Modes() {
+		if spec.Prompt(ws) == "" {
+			t.Errorf("mode %q has an empty prompt", spec.Name)
+		}
+		layout := spec.Layout(ws, "agent-cmd")
+		if layout.Name != string(spec.Name) {
+			t.Errorf("mode %q layout name = %q", spec.Name, layout.Name)
+		}
+		if len(layout.Panes) == 0 || layout.Panes[0].Command != "agent-cmd" {
+			t.Errorf("mode %q layout does not run the agent in pane 0", spec.Name)
+		}
+		for _, target := range spec.Transitions {
+			if _, err := LookupMode(target); err != nil {
+				t.Errorf("mode %q transitions to unregistered mode %q", spec.Name, target)
+			}
+		}
+	}
+}
+
+func TestRegisterMode_Duplicate(t *testing.T) {
+	err := RegisterMode(ModeSpec{
+		Name:   ModePlan,
+		Prompt: (*Workspace).planPrompt,
+		Layout: func(w *Workspace, agentCmd string) tmux.Layout { return tmux.Layout{} },
+	})
+	if err == nil {
+		t.Error("RegisterMode() accepted a duplicate mode")
+	}
+}
+
+func TestSetMode_Transitions(t *testing.T) {
+	ws := newModeWorkspace(t)
+
+	steps := []struct {
+		mode    Mode
+		wantErr bool
+	}{
+		{ModeExecute, false},
+		{ModeReview, false},
+		{ModeTest, true}, // review cannot go to test
+		{ModePlan, false},
+		{Mode("bogus"), true},
+	}
+
+	for _, step := range steps {
+		err := ws.SetMode(step.mode, SwitchOptions{})
+		if (err != nil) != step.wantErr {
+			t.Fatalf("SetMode(%q) error = %v, wantErr %v", step.mode, err, step.wantErr)
+		}
+	}
+
+	if mode, _ := ws.GetMode(); mode != ModePlan {
+		t.Errorf("GetMode() = %q, want %q", mode, ModePlan)
+	}
+}
+
+func TestToggleMode(t *testing.T) {
+	ws := newModeWorkspace(t)
+
+	want := []Mode{ModeExecute, ModePlan, ModeExecute}
+	for _, w := range want {
+		got, err := ws.ToggleMode(SwitchOptions{})
+		if err != nil {
+			t.Fatalf("ToggleMode() failed: %v", err)
+		}
+		if got != w {
+			t.Errorf("ToggleMode() = %q, want %q", got, w)
+		}
+	}
+}
+
+func TestModeHistory(t *testing.T) {
+	ws := newModeWorkspace(t)
+
+	if err := ws.SetMode(ModeExecute, SwitchOptions{Trigger: TriggerKeybinding}); err != nil {
+		t.Fatalf("SetMode() failed: %v", err)
+	}
+	// Re-entering the current mode is not a transition
+	if err := ws.SetMode(ModeExecute, SwitchOptions{}); err != nil {
+		t.Fatalf("SetMode() failed: %v", err)
+	}
+	if err := ws.SetMode(ModePlan, SwitchOptions{}); err != nil {
+		t.Fatalf("SetMode() failed: %v", err)
+	}
+
+	history, err := ws.ModeHistory()
+	if err != nil {
+		t.Fatalf("ModeHistory() failed: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("ModeHistory() returned %d entries, want 2", len(history))
+	}
+
+	first := history[0]
+	if first.From != ModePlan || first.To != ModeExecute || first.Trigger != TriggerKeybinding {
+		t.Errorf("first transition = %+v", first)
+	}
+	if hash, _ := ws.PlanHash(); first.PlanHash != hash || hash == "" {
+		t.Errorf("first transition plan hash = %q, want %q", first.PlanHash, hash)
+	}
+	if history[1].Trigger != TriggerCLI {
+		t.Errorf("default trigger = %q, want %q", history[1].Trigger, TriggerCLI)
+	}
+}
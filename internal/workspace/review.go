@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"planq.dev/planq/internal/statefile"
 )
 
 // ReviewState tracks whether a workspace needs review.
@@ -55,7 +57,7 @@ func (w *Workspace) SetNeedsReview() error {
 		return fmt.Errorf("failed to marshal review state: %w", err)
 	}
 
-	if err := os.WriteFile(w.ReviewFile(), data, 0644); err != nil {
+	if err := statefile.WriteFile(w.ReviewFile(), data, 0644); err != nil {
 		return fmt.Errorf("failed to write review file: %w", err)
 	}
 
@@ -74,7 +76,7 @@ func (w *Workspace) ClearReview() error {
 		return fmt.Errorf("failed to marshal review state: %w", err)
 	}
 
-	if err := os.WriteFile(w.ReviewFile(), data, 0644); err != nil {
+	if err := statefile.WriteFile(w.ReviewFile(), data, 0644); err != nil {
 		return fmt.Errorf("failed to write review file: %w", err)
 	}
 
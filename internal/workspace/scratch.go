@@ -0,0 +1,112 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"planq.dev/planq/internal/statefile"
+)
+
+const (
+	// MaxScratchSize caps the scratch pad so that reading it back stays cheap.
+	MaxScratchSize = 64 * 1024
+	// MaxScratchAppend caps a single appended section.
+	MaxScratchAppend = 8 * 1024
+
+	// scratchTimeFormat stamps appended sections.
+	scratchTimeFormat = "2006-01-02 15:04"
+	// initialScratch is the content of a new scratch pad.
+	initialScratch = "# Scratch\n\nWorking notes for this session.\n"
+)
+
+// ScratchFile returns the path to the agent's scratch pad.
+func (w *Workspace) ScratchFile() string {
+	return filepath.Join(w.AgentDir(), "scratch.md")
+}
+
+// ReadScratch returns the scratch pad, or an empty string if there is none.
+func (w *Workspace) ReadScratch() (string, error) {
+	data, err := os.ReadFile(w.ScratchFile())
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read scratch pad: %w", err)
+	}
+	return string(data), nil
+}
+
+// AppendScratch adds a timestamped section to the scratch pad, headed by
+// header when given, and returns the section.
+func (w *Workspace) AppendScratch(text, header string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fmt.Errorf("scratch text is empty")
+	}
+	if len(text) > MaxScratchAppend {
+		return "", fmt.Errorf("scratch section is %d bytes, the limit is %d", len(text), MaxScratchAppend)
+	}
+
+	title := time.Now().Format(scratchTimeFormat)
+	if header = strings.TrimSpace(strings.TrimLeft(header, "# ")); header != "" {
+		title = header + " (" + title + ")"
+	}
+	section := fmt.Sprintf("## %s\n\n%s\n", title, text)
+
+	err := w.updateScratch(func(content string) (string, error) {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		if content != "" {
+			content += "\n"
+		}
+		return content + section, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return section, nil
+}
+
+// ReplaceScratch overwrites the scratch pad, e.g. with a summary of it.
+func (w *Workspace) ReplaceScratch(content string) error {
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return w.updateScratch(func(string) (string, error) {
+		return content, nil
+	})
+}
+
+// updateScratch rewrites the scratch pad under its lock, enforcing MaxScratchSize.
+func (w *Workspace) updateScratch(fn func(content string) (string, error)) error {
+	if err := os.MkdirAll(w.AgentDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create agent directory: %w", err)
+	}
+
+	lock, err := statefile.Acquire(w.ScratchFile())
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	content, err := w.ReadScratch()
+	if err != nil {
+		return err
+	}
+	content, err = fn(content)
+	if err != nil {
+		return err
+	}
+	if len(content) > MaxScratchSize {
+		return fmt.Errorf("scratch pad would be %d bytes, the limit is %d: replace it with a summary first", len(content), MaxScratchSize)
+	}
+
+	if err := statefile.WriteFile(w.ScratchFile(), []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write scratch pad: %w", err)
+	}
+	return nil
+}
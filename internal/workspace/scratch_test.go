@@ -0,0 +1,47 @@
+package workspace
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScratch(t *testing.T) {
+	ws := &Workspace{Name: "notes", WorktreePath: t.TempDir()}
+
+	content, err := ws.ReadScratch()
+	if err != nil || content != "" {
+		t.Fatalf("ReadScratch() = %q, %v; want empty", content, err)
+	}
+
+	if err := ws.ReplaceScratch(initialScratch); err != nil {
+		t.Fatalf("ReplaceScratch() failed: %v", err)
+	}
+	section, err := ws.AppendScratch("Auth lives in internal/auth.", "## Findings")
+	if err != nil {
+		t.Fatalf("AppendScratch() failed: %v", err)
+	}
+	if !strings.HasPrefix(section, "## Findings (") || !strings.HasSuffix(section, "\n\nAuth lives in internal/auth.\n") {
+		t.Errorf("section = %q", section)
+	}
+	if _, err := ws.AppendScratch("Second note", ""); err != nil {
+		t.Fatalf("AppendScratch() failed: %v", err)
+	}
+
+	content, err = ws.ReadScratch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(content, initialScratch+"\n## Findings (") || !strings.HasSuffix(content, "\n\nSecond note\n") {
+		t.Errorf("scratch = %q", content)
+	}
+
+	if _, err := ws.AppendScratch("   ", ""); err == nil {
+		t.Error("AppendScratch() accepted empty text")
+	}
+	if _, err := ws.AppendScratch(strings.Repeat("x", MaxScratchAppend+1), ""); err == nil {
+		t.Error("AppendScratch() accepted an oversized section")
+	}
+	if err := ws.ReplaceScratch(strings.Repeat("x", MaxScratchSize+1)); err == nil {
+		t.Error("ReplaceScratch() accepted an oversized pad")
+	}
+}
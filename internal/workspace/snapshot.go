@@ -0,0 +1,161 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"planq.dev/planq/internal/statefile"
+)
+
+// Reasons recorded with plan snapshots.
+const (
+	SnapshotApprove = "approve"
+	SnapshotExecute = "execute"
+)
+
+// snapshotTimeFormat is the timestamp prefix of snapshot file names.
+const snapshotTimeFormat = "2006-01-02T15-04-05"
+
+// PlanSnapshot is a saved revision of the plan file.
+type PlanSnapshot struct {
+	// ID is the snapshot file name without the .md extension.
+	ID        string
+	Path      string
+	Reason    string
+	CreatedAt time.Time
+	// seq orders the snapshots taken within the same second, from 1.
+	seq int
+}
+
+// PlanSnapshotsDir returns the path to the .planq/artifacts/plans directory.
+func (w *Workspace) PlanSnapshotsDir() string {
+	return filepath.Join(w.ArtifactsDir(), "plans")
+}
+
+// SnapshotPlan copies the current plan file into the snapshots directory.
+func (w *Workspace) SnapshotPlan(reason string) (*PlanSnapshot, error) {
+	// A missing plan file is snapshotted as an empty plan
+	data, err := os.ReadFile(w.PlanFile())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+
+	dir := w.PlanSnapshotsDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshots directory: %w", err)
+	}
+
+	// Snapshots taken in the same second are numbered in the order they were
+	// taken, whatever their reason
+	now := time.Now()
+	timestamp := now.Format(snapshotTimeFormat)
+	seq := 1
+	if matches, err := filepath.Glob(filepath.Join(dir, timestamp+"-*.md")); err == nil {
+		seq = len(matches) + 1
+	}
+	var id, path string
+	for ; ; seq++ {
+		id = timestamp + "-" + reason
+		if seq > 1 {
+			id = fmt.Sprintf("%s-%d", id, seq)
+		}
+		path = filepath.Join(dir, id+".md")
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+	}
+
+	if err := statefile.WriteFile(path, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write plan snapshot: %w", err)
+	}
+
+	return &PlanSnapshot{ID: id, Path: path, Reason: reason, CreatedAt: now, seq: seq}, nil
+}
+
+// PlanSnapshots returns all plan snapshots, oldest first.
+func (w *Workspace) PlanSnapshots() ([]PlanSnapshot, error) {
+	entries, err := os.ReadDir(w.PlanSnapshotsDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read snapshots directory: %w", err)
+	}
+
+	var snapshots []PlanSnapshot
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			continue
+		}
+		snapshot, ok := parseSnapshotName(entry.Name())
+		if !ok {
+			continue
+		}
+		snapshot.Path = filepath.Join(w.PlanSnapshotsDir(), entry.Name())
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		a, b := snapshots[i], snapshots[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		if a.seq != b.seq {
+			return a.seq < b.seq
+		}
+		return a.ID < b.ID
+	})
+
+	return snapshots, nil
+}
+
+// FindPlanSnapshot resolves a snapshot by its 1-based position in PlanSnapshots or by ID.
+func (w *Workspace) FindPlanSnapshot(ref string) (*PlanSnapshot, error) {
+	snapshots, err := w.PlanSnapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(snapshots) {
+			return nil, fmt.Errorf("no plan revision %d (have %d)", n, len(snapshots))
+		}
+		return &snapshots[n-1], nil
+	}
+
+	ref = strings.TrimSuffix(ref, ".md")
+	for i := range snapshots {
+		if snapshots[i].ID == ref {
+			return &snapshots[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("no plan revision %q", ref)
+}
+
+// parseSnapshotName parses "<timestamp>-<reason>[-n].md".
+func parseSnapshotName(name string) (PlanSnapshot, bool) {
+	id := strings.TrimSuffix(name, ".md")
+	if len(id) <= len(snapshotTimeFormat)+1 {
+		return PlanSnapshot{}, false
+	}
+
+	createdAt, err := time.ParseInLocation(snapshotTimeFormat, id[:len(snapshotTimeFormat)], time.Local)
+	if err != nil {
+		return PlanSnapshot{}, false
+	}
+
+	reason, seq := id[len(snapshotTimeFormat)+1:], 1
+	if i := strings.LastIndex(reason, "-"); i > 0 {
+		if n, err := strconv.Atoi(reason[i+1:]); err == nil {
+			reason, seq = reason[:i], n
+		}
+	}
+
+	return PlanSnapshot{ID: id, Reason: reason, CreatedAt: createdAt, seq: seq}, true
+}
@@ -0,0 +1,105 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPlanSnapshots(t *testing.T) {
+	ws := newModeWorkspace(t) // approving the plan takes the first snapshot
+
+	if err := ws.SetMode(ModeExecute, SwitchOptions{}); err != nil {
+		t.Fatalf("SetMode(execute) failed: %v", err)
+	}
+	if _, err := ws.SnapshotPlan(SnapshotExecute); err != nil {
+		t.Fatalf("SnapshotPlan() failed: %v", err)
+	}
+
+	snapshots, err := ws.PlanSnapshots()
+	if err != nil {
+		t.Fatalf("PlanSnapshots() failed: %v", err)
+	}
+	reasons := []string{SnapshotApprove, SnapshotExecute, SnapshotExecute}
+	if len(snapshots) != len(reasons) {
+		t.Fatalf("PlanSnapshots() returned %d snapshots, want %d", len(snapshots), len(reasons))
+	}
+	for i, reason := range reasons {
+		if snapshots[i].Reason != reason {
+			t.Errorf("snapshot %d reason = %q, want %q", i+1, snapshots[i].Reason, reason)
+		}
+	}
+
+	content, err := os.ReadFile(snapshots[0].Path)
+	if err != nil {
+		t.Fatalf("Failed to read snapshot: %v", err)
+	}
+	if string(content) != "# Plan\n" {
+		t.Errorf("snapshot content = %q", content)
+	}
+
+	byIndex, err := ws.FindPlanSnapshot("2")
+	if err != nil || byIndex.ID != snapshots[1].ID {
+		t.Errorf("FindPlanSnapshot(\"2\") = %v, %v", byIndex, err)
+	}
+	byID, err := ws.FindPlanSnapshot(snapshots[2].ID + ".md")
+	if err != nil || byID.ID != snapshots[2].ID {
+		t.Errorf("FindPlanSnapshot(id) = %v, %v", byID, err)
+	}
+	if _, err := ws.FindPlanSnapshot("4"); err == nil {
+		t.Error("FindPlanSnapshot(\"4\") succeeded, want error")
+	}
+}
+
+func TestPlanSnapshotsSameSecond(t *testing.T) {
+	ws := newModeWorkspace(t)
+	dir := ws.PlanSnapshotsDir()
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Taken in this order, the last three within one second
+	ids := []string{
+		"2025-01-01T09-59-59-execute",
+		"2025-01-01T10-00-00-execute",
+		"2025-01-01T10-00-00-approve-2",
+		"2025-01-01T10-00-00-execute-10",
+	}
+	for _, id := range ids {
+		if err := os.WriteFile(filepath.Join(dir, id+".md"), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snapshots, err := ws.PlanSnapshots()
+	if err != nil {
+		t.Fatalf("PlanSnapshots() failed: %v", err)
+	}
+	var got []string
+	for _, snapshot := range snapshots {
+		got = append(got, snapshot.ID)
+	}
+	if !slices.Equal(got, ids) {
+		t.Errorf("PlanSnapshots() = %v, want %v", got, ids)
+	}
+
+	// New snapshots keep the order they were taken in, whatever their reason
+	if _, err := ws.SnapshotPlan(SnapshotExecute); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.SnapshotPlan(SnapshotApprove); err != nil {
+		t.Fatal(err)
+	}
+	snapshots, err = ws.PlanSnapshots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := snapshots[len(snapshots)-2:]
+	if last[0].Reason != SnapshotExecute || last[1].Reason != SnapshotApprove {
+		t.Errorf("latest snapshots = %s, %s; want execute then approve", last[0].ID, last[1].ID)
+	}
+}
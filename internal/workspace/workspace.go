@@ -26,6 +26,12 @@ const (
 type Workspace struct {
 	Name         string
 	WorktreePath string
+	// AgentName selects the agent backend (default: claude).
+	AgentName string
+	// AgentBinary overrides the agent executable.
+	AgentBinary string
+	// InitialPrompt is sent to the agent when it is first launched.
+	InitialPrompt string
 }
 
 // PlanqDir returns the path to the .planq directory.
@@ -43,12 +49,17 @@ func (w *Workspace) PlanFile() string {
 	return filepath.Join(w.PlanqDir(), w.Name+".md")
 }
 
-// InitPlanqDir creates the .planq directory structure and empty plan file.
-func (w *Workspace) InitPlanqDir() error {
+// ArtifactsDir returns the path to the .planq/artifacts directory.
+func (w *Workspace) ArtifactsDir() string {
+	return filepath.Join(w.PlanqDir(), "artifacts")
+}
+
+// InitPlanqDir creates the .planq directory structure and the plan file
+// with the given initial content (empty if nil).
+func (w *Workspace) InitPlanqDir(plan []byte) error {
 	dirs := []string{
 		w.PlanqDir(),
-		filepath.Join(w.PlanqDir(), "artifacts"),
-		w.ClaudeCommandsDir(),
+		w.ArtifactsDir(),
 	}
 
 	for _, dir := range dirs {
@@ -57,68 +68,96 @@ func (w *Workspace) InitPlanqDir() error {
 		}
 	}
 
-	// Create empty plan file so glow has something to display
+	// Create the plan file so glow has something to display
 	planFile := w.PlanFile()
-	if err := os.WriteFile(planFile, []byte{}, 0644); err != nil {
+	if err := os.WriteFile(planFile, plan, 0644); err != nil {
 		return fmt.Errorf("failed to create plan file %s: %w", planFile, err)
 	}
 
-	// Create planq-mode skill for Claude
-	skillFile := filepath.Join(w.ClaudeCommandsDir(), "planq-mode.md")
-	if err := os.WriteFile(skillFile, []byte(planqModeSkill), 0644); err != nil {
-		return fmt.Errorf("failed to create skill file %s: %w", skillFile, err)
-	}
-
 	// Initialize mode to plan
-	if err := w.SetMode(ModePlan); err != nil {
+	if err := w.writeMode(ModePlan); err != nil {
 		return fmt.Errorf("failed to initialize mode: %w", err)
 	}
 
 	return nil
 }
 
-// AgentCommand returns the Claude command configured for the current mode.
-func (w *Workspace) AgentCommand() string {
+// AgentCommand returns the agent launch command configured for the current mode.
+func (w *Workspace) AgentCommand() (string, error) {
 	mode, err := w.GetMode()
 	if err != nil {
 		mode = ModePlan // default to plan mode on error
 	}
 
-	switch mode {
-	case ModeExecute:
-		return w.executeAgentCommand()
-	default:
-		return w.planAgentCommand()
+	agent, err := w.Agent()
+	if err != nil {
+		return "", err
 	}
+
+	spec, err := LookupMode(mode)
+	if err != nil {
+		return "", err
+	}
+
+	return agent.LaunchCommand(w, LaunchOptions{
+		Binary:        w.AgentBinary,
+		SystemPrompt:  spec.Prompt(w),
+		InitialPrompt: w.InitialPrompt,
+	})
 }
 
-// planAgentCommand returns the Claude command for plan mode.
-func (w *Workspace) planAgentCommand() string {
-	planFile := w.PlanFile()
-	systemPrompt := fmt.Sprintf(
+// planPrompt returns the agent instructions for plan mode.
+func (w *Workspace) planPrompt() string {
+	return fmt.Sprintf(
 		"You are in planning mode for the planq workspace %q. "+
 			"You MUST write your implementation plan to %s. This is a REQUIREMENT. "+
 			"Do NOT make any code changes. Do NOT use any other file for planning. "+
 			"Read from and write to ONLY this plan file. "+
 			"This file will be displayed in the artifacts pane for user review. "+
-			"Wait for explicit user approval before proceeding with any implementation.",
+			"Wait for explicit user approval before proceeding with any implementation. "+
+			"The user approves the plan with 'planq plan approve'; never run it yourself.",
 		w.Name,
-		planFile,
+		w.PlanFile(),
 	)
-	return fmt.Sprintf("claude --append-system-prompt %q", systemPrompt)
 }
 
-// executeAgentCommand returns the Claude command for execute mode.
-func (w *Workspace) executeAgentCommand() string {
-	planFile := w.PlanFile()
-	systemPrompt := fmt.Sprintf(
+// executePrompt returns the agent instructions for execute mode.
+func (w *Workspace) executePrompt() string {
+	return fmt.Sprintf(
 		"You are in execution mode for the planq workspace %q. "+
 			"Follow the implementation plan at %s. "+
-			"Implement each step carefully.",
+			"Implement each step carefully and mark checklist steps done as you complete them "+
+			"(use the planq_plan_check tool if available). "+
+			"Keep working notes in the scratch pad at %s (use the planq_scratch_append tool if available) "+
+			"and read it when resuming work.",
 		w.Name,
-		planFile,
+		w.PlanFile(),
+		w.ScratchFile(),
+	)
+}
+
+// reviewPrompt returns the agent instructions for review mode.
+func (w *Workspace) reviewPrompt() string {
+	return fmt.Sprintf(
+		"You are in review mode for the planq workspace %q. "+
+			"Review the uncommitted and committed changes in this worktree against the plan at %s. "+
+			"Do NOT make any code changes. "+
+			"Check each plan step is implemented correctly, and look for bugs, missing tests and unclear code. "+
+			"Report your findings as a prioritized list.",
+		w.Name,
+		w.PlanFile(),
+	)
+}
+
+// testPrompt returns the agent instructions for test mode.
+func (w *Workspace) testPrompt() string {
+	return fmt.Sprintf(
+		"You are in test mode for the planq workspace %q. "+
+			"Write and run tests covering the changes described in the plan at %s. "+
+			"Only change production code to fix failures the tests uncover, and explain each such fix.",
+		w.Name,
+		w.PlanFile(),
 	)
-	return fmt.Sprintf("claude --append-system-prompt %q", systemPrompt)
 }
 
 // AgentDir returns the path to the .planq/agent directory.
@@ -126,6 +165,11 @@ func (w *Workspace) AgentDir() string {
 	return filepath.Join(w.PlanqDir(), AgentSubdirName)
 }
 
+// ChangelogFile returns the path to the agent's changelog.
+func (w *Workspace) ChangelogFile() string {
+	return filepath.Join(w.AgentDir(), "changelog.md")
+}
+
 // InitAgentDir creates the .planq/agent directory structure with initial files.
 func (w *Workspace) InitAgentDir() error {
 	agentDir := w.AgentDir()
@@ -142,9 +186,7 @@ func (w *Workspace) InitAgentDir() error {
 	}
 
 	// Create initial scratch.md
-	scratchFile := filepath.Join(agentDir, "scratch.md")
-	scratchContent := []byte("# Scratch\n\nWorking notes for this session.\n")
-	if err := os.WriteFile(scratchFile, scratchContent, 0644); err != nil {
+	if err := os.WriteFile(w.ScratchFile(), []byte(initialScratch), 0644); err != nil {
 		return fmt.Errorf("failed to create scratch file: %w", err)
 	}
 
@@ -153,9 +195,9 @@ func (w *Workspace) InitAgentDir() error {
 		return fmt.Errorf("failed to update .gitignore: %w", err)
 	}
 
-	// Configure Claude to use agent plans directory
-	if err := w.ConfigureClaudeSettings(); err != nil {
-		return fmt.Errorf("failed to configure Claude settings: %w", err)
+	// Install the agent's skill and settings files
+	if err := w.InstallAgent(); err != nil {
+		return err
 	}
 
 	return nil